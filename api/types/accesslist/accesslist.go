@@ -0,0 +1,91 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package accesslist defines the access list resource: a group of users
+// granted a set of roles/traits, reviewed on a recurring audit schedule.
+package accesslist
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Audit describes an access list's recurring review schedule.
+type Audit struct {
+	// Frequency is how often the access list must be reviewed, expressed
+	// as a fixed interval. It remains the source of truth for NextAuditDate
+	// when Schedule is unset, preserving every existing access list's
+	// "1h0m0s"-style JSON representation.
+	Frequency time.Duration
+	// NextAuditDate is when the next review is due.
+	NextAuditDate time.Time
+	// Schedule, if set, supersedes Frequency with a calendar-aware
+	// recurrence (a cron expression evaluated in an IANA timezone) so
+	// audits can be expressed as "every first Monday" instead of a fixed
+	// duration. See AuditSchedule.
+	Schedule *AuditSchedule
+}
+
+type auditJSON struct {
+	Frequency     string         `json:"frequency"`
+	NextAuditDate time.Time      `json:"next_audit_date"`
+	Schedule      *AuditSchedule `json:"schedule,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, rendering Frequency as a duration
+// string (e.g. "1h0m0s") rather than time.Duration's default nanosecond
+// count.
+func (a Audit) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(auditJSON{
+		Frequency:     a.Frequency.String(),
+		NextAuditDate: a.NextAuditDate,
+		Schedule:      a.Schedule,
+	})
+	return data, trace.Wrap(err)
+}
+
+type auditUnmarshalJSON struct {
+	Frequency     string          `json:"frequency"`
+	NextAuditDate json.RawMessage `json:"next_audit_date"`
+	Schedule      *AuditSchedule  `json:"schedule,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing Frequency from its
+// duration string form and NextAuditDate with ParseFlexibleTime, so
+// operator-supplied audit dates aren't limited to strict RFC3339.
+func (a *Audit) UnmarshalJSON(data []byte) error {
+	var v auditUnmarshalJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return trace.Wrap(err)
+	}
+
+	duration, err := time.ParseDuration(v.Frequency)
+	if err != nil {
+		return trace.BadParameter("invalid frequency %q: %v", v.Frequency, err)
+	}
+
+	nextAuditDate, err := ParseFlexibleTime(v.NextAuditDate, time.Now())
+	if err != nil {
+		return trace.Wrap(err, "parsing next_audit_date")
+	}
+
+	a.Frequency = duration
+	a.NextAuditDate = nextAuditDate
+	a.Schedule = v.Schedule
+	return nil
+}
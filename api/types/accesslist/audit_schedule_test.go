@@ -0,0 +1,62 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMarshalingWithSchedule(t *testing.T) {
+	audit := Audit{
+		Frequency:     time.Hour,
+		NextAuditDate: time.Date(2023, 02, 02, 0, 0, 0, 0, time.UTC),
+		Schedule:      &AuditSchedule{Cron: "0 9 1 1,4,7,10 *", Timezone: "America/New_York"},
+	}
+
+	data, err := json.Marshal(&audit)
+	require.NoError(t, err)
+
+	var got Audit
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, audit.Frequency, got.Frequency)
+	require.True(t, audit.NextAuditDate.Equal(got.NextAuditDate))
+	require.Equal(t, *audit.Schedule, *got.Schedule)
+}
+
+func TestAuditMarshalingWithoutScheduleOmitsField(t *testing.T) {
+	audit := Audit{
+		Frequency:     time.Hour,
+		NextAuditDate: time.Date(2023, 02, 02, 0, 0, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(&audit)
+	require.NoError(t, err)
+
+	raw := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	require.NotContains(t, raw, "schedule")
+}
+
+func TestAuditUnmarshalingRejectsInvalidFrequency(t *testing.T) {
+	var audit Audit
+	err := json.Unmarshal([]byte(`{"frequency":"not-a-duration","next_audit_date":"2023-02-02T00:00:00Z"}`), &audit)
+	require.Error(t, err)
+}
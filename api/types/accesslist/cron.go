@@ -0,0 +1,180 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// cronExpr is a parsed standard 5-field cron expression: minute, hour,
+// day-of-month, month, day-of-week. A nil field set means "every value",
+// i.e. the field was "*". This intentionally supports only "*" and
+// comma-separated integer lists, not ranges or steps, plus one extension
+// on the day-of-week field: "dow#n" (e.g. "1#1") matches only the nth
+// occurrence of that weekday in the month, which is what expresses
+// recurrences like "every first Monday"; see AuditSchedule's doc comment
+// for the intended use.
+type cronExpr struct {
+	minute, hour, dom, month, dow map[int]bool
+	// dowNth maps a weekday to the set of its nth-in-month occurrences
+	// ("1#1,1#3" allows the first and third Monday); nil if the day-of-week
+	// field used no "#n" terms.
+	dowNth map[int]map[int]bool
+}
+
+func parseCronExpr(expr string) (*cronExpr, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, trace.BadParameter("expected 5 fields (minute hour dom month dow), got %d", len(fields))
+	}
+
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, trace.Wrap(err, "minute field")
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, trace.Wrap(err, "hour field")
+	}
+	dom, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, trace.Wrap(err, "day-of-month field")
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, trace.Wrap(err, "month field")
+	}
+	dow, dowNth, err := parseDowField(fields[4])
+	if err != nil {
+		return nil, trace.Wrap(err, "day-of-week field")
+	}
+
+	return &cronExpr{minute: minute, hour: hour, dom: dom, month: month, dow: dow, dowNth: dowNth}, nil
+}
+
+// parseDowField parses the day-of-week field, which extends the plain
+// comma-separated integer list every other field uses with an optional
+// "#n" suffix per term (e.g. "1#1" is "the first Monday"). Terms without
+// "#" are returned in dow and match every occurrence of that weekday;
+// terms with "#" are returned in dowNth and match only their nth
+// occurrence in the month.
+func parseDowField(field string) (dow map[int]bool, dowNth map[int]map[int]bool, err error) {
+	if field == "*" {
+		return nil, nil, nil
+	}
+
+	dow = make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		dayPart, nthPart, hasNth := strings.Cut(part, "#")
+
+		day, err := strconv.Atoi(dayPart)
+		if err != nil || day < 0 || day > 6 {
+			return nil, nil, trace.BadParameter("invalid day-of-week value %q", dayPart)
+		}
+
+		if !hasNth {
+			dow[day] = true
+			continue
+		}
+
+		nth, err := strconv.Atoi(nthPart)
+		if err != nil || nth < 1 || nth > 5 {
+			return nil, nil, trace.BadParameter("invalid day-of-week occurrence %q, expected 1-5", nthPart)
+		}
+		if dowNth == nil {
+			dowNth = make(map[int]map[int]bool)
+		}
+		if dowNth[day] == nil {
+			dowNth[day] = make(map[int]bool)
+		}
+		dowNth[day][nth] = true
+	}
+
+	return dow, dowNth, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	if field == "*" {
+		return nil, nil
+	}
+
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, trace.BadParameter("invalid value %q", part)
+		}
+		if n < min || n > max {
+			return nil, trace.BadParameter("value %d out of range [%d, %d]", n, min, max)
+		}
+		values[n] = true
+	}
+	return values, nil
+}
+
+// next returns the first minute-aligned time strictly after after that
+// matches e, within maxCronLookahead. It reports false if no match was
+// found in that window.
+func (e *cronExpr) next(after time.Time) (time.Time, bool) {
+	loc := after.Location()
+	candidate := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+	deadline := after.Add(maxCronLookahead)
+
+	for candidate.Before(deadline) {
+		if e.matches(candidate) {
+			return candidate, true
+		}
+		candidate = candidate.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
+
+func (e *cronExpr) matches(t time.Time) bool {
+	return matchesCronField(e.minute, t.Minute()) &&
+		matchesCronField(e.hour, t.Hour()) &&
+		matchesCronField(e.dom, t.Day()) &&
+		matchesCronField(e.month, int(t.Month())) &&
+		e.matchesDow(t)
+}
+
+func matchesCronField(field map[int]bool, value int) bool {
+	return field == nil || field[value]
+}
+
+// matchesDow reports whether t's weekday satisfies the day-of-week field,
+// accounting for any "#n" nth-occurrence-in-month terms.
+func (e *cronExpr) matchesDow(t time.Time) bool {
+	if e.dow == nil && e.dowNth == nil {
+		return true
+	}
+
+	weekday := int(t.Weekday())
+	if e.dow[weekday] {
+		return true
+	}
+	return e.dowNth[weekday][nthWeekdayOfMonth(t)]
+}
+
+// nthWeekdayOfMonth returns which occurrence of its weekday t is within its
+// month: 1 for the 1st-7th, 2 for the 8th-14th, and so on.
+func nthWeekdayOfMonth(t time.Time) int {
+	return (t.Day()-1)/7 + 1
+}
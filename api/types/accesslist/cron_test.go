@@ -0,0 +1,89 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronExpr(t *testing.T) {
+	_, err := parseCronExpr("0 9 * * 1")
+	require.NoError(t, err)
+
+	_, err = parseCronExpr("0 9 * *")
+	require.Error(t, err)
+
+	_, err = parseCronExpr("60 9 * * 1")
+	require.Error(t, err)
+
+	_, err = parseCronExpr("x 9 * * 1")
+	require.Error(t, err)
+
+	_, err = parseCronExpr("0 9 * * 1#1")
+	require.NoError(t, err)
+
+	_, err = parseCronExpr("0 9 * * 1#6")
+	require.Error(t, err)
+}
+
+func TestCronExprNext(t *testing.T) {
+	expr, err := parseCronExpr("0 9 * * 1")
+	require.NoError(t, err)
+
+	// 2024-01-01 is a Monday.
+	after := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	next, ok := expr.next(after)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), next)
+
+	// After 9am Monday, the next match is the following Monday.
+	after = time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	next, ok = expr.next(after)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC), next)
+}
+
+func TestCronExprNextFirstMonday(t *testing.T) {
+	expr, err := parseCronExpr("0 9 * * 1#1")
+	require.NoError(t, err)
+
+	// 2024-01-01 is the first Monday of January.
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next, ok := expr.next(after)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), next)
+
+	// After the first Monday, the next match skips every other Monday in
+	// January and lands on the first Monday of February.
+	after = time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	next, ok = expr.next(after)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC), next)
+}
+
+func TestCronExprNextQuarterly(t *testing.T) {
+	expr, err := parseCronExpr("0 9 15 1,4,7,10 *")
+	require.NoError(t, err)
+
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	next, ok := expr.next(after)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC), next)
+}
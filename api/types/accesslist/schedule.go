@@ -0,0 +1,117 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// maxCronLookahead bounds how far NextAuditDate will search for the next
+// matching minute, so a cron expression that (due to a bug or an
+// impossible combination, e.g. "Feb 30") never matches fails fast instead
+// of looping forever.
+const maxCronLookahead = 4 * 366 * 24 * time.Hour
+
+// AuditSchedule is an access list audit's recurrence, in either of two
+// forms:
+//   - a fixed duration (e.g. "1h0m0s"), the original Audit.Frequency shape,
+//     preserved here for backwards compatibility with existing JSON; or
+//   - a cron expression evaluated in an IANA timezone, so audits can land
+//     on a calendar-aware cadence ("every first Monday", "quarterly on the
+//     15th at 09:00") without drifting an hour across DST transitions.
+type AuditSchedule struct {
+	// Duration is the fixed-interval form. Zero when Cron is set.
+	Duration time.Duration
+	// Cron is a 5-field cron expression ("minute hour dom month dow").
+	// Empty when Duration is set.
+	Cron string
+	// Timezone is the IANA zone Cron is evaluated in, e.g.
+	// "America/New_York".
+	Timezone string
+}
+
+type auditScheduleJSON struct {
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s AuditSchedule) MarshalJSON() ([]byte, error) {
+	if s.Cron == "" {
+		data, err := json.Marshal(s.Duration.String())
+		return data, trace.Wrap(err)
+	}
+	data, err := json.Marshal(auditScheduleJSON{Cron: s.Cron, Timezone: s.Timezone})
+	return data, trace.Wrap(err)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either a duration
+// string or a {cron, timezone} object. Invalid timezones and cron
+// expressions are rejected with a trace.BadParameter error.
+func (s *AuditSchedule) UnmarshalJSON(data []byte) error {
+	var duration string
+	if err := json.Unmarshal(data, &duration); err == nil {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			return trace.BadParameter("invalid schedule duration %q: %v", duration, err)
+		}
+		*s = AuditSchedule{Duration: parsed}
+		return nil
+	}
+
+	var v auditScheduleJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return trace.BadParameter("schedule must be a duration string or a {cron, timezone} object: %v", err)
+	}
+	if _, err := time.LoadLocation(v.Timezone); err != nil {
+		return trace.BadParameter("invalid timezone %q: %v", v.Timezone, err)
+	}
+	if _, err := parseCronExpr(v.Cron); err != nil {
+		return trace.BadParameter("invalid cron expression %q: %v", v.Cron, err)
+	}
+
+	*s = AuditSchedule{Cron: v.Cron, Timezone: v.Timezone}
+	return nil
+}
+
+// NextAuditDate returns the first occurrence of s strictly after after. For
+// the duration form this is simply after.Add(Duration); for the cron form
+// it's the next minute matching Cron, computed in Timezone so DST
+// transitions shift the UTC instant rather than the local wall-clock time.
+func (s AuditSchedule) NextAuditDate(after time.Time) (time.Time, error) {
+	if s.Cron == "" {
+		return after.Add(s.Duration), nil
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, trace.BadParameter("invalid timezone %q: %v", s.Timezone, err)
+	}
+	expr, err := parseCronExpr(s.Cron)
+	if err != nil {
+		return time.Time{}, trace.BadParameter("invalid cron expression %q: %v", s.Cron, err)
+	}
+
+	next, ok := expr.next(after.In(loc))
+	if !ok {
+		return time.Time{}, trace.BadParameter("cron expression %q has no occurrence within %s of %s", s.Cron, maxCronLookahead, after)
+	}
+	return next, nil
+}
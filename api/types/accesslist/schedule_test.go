@@ -0,0 +1,85 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditScheduleDurationRoundTrip(t *testing.T) {
+	schedule := AuditSchedule{Duration: time.Hour}
+
+	data, err := json.Marshal(schedule)
+	require.NoError(t, err)
+	require.Equal(t, `"1h0m0s"`, string(data))
+
+	var got AuditSchedule
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, schedule, got)
+}
+
+func TestAuditScheduleCronRoundTrip(t *testing.T) {
+	schedule := AuditSchedule{Cron: "0 9 1 1,4,7,10 *", Timezone: "America/New_York"}
+
+	data, err := json.Marshal(schedule)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"cron":"0 9 1 1,4,7,10 *","timezone":"America/New_York"}`, string(data))
+
+	var got AuditSchedule
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, schedule, got)
+}
+
+func TestAuditScheduleUnmarshalRejectsInvalidTimezone(t *testing.T) {
+	var schedule AuditSchedule
+	err := json.Unmarshal([]byte(`{"cron":"0 9 * * *","timezone":"Not/A_Zone"}`), &schedule)
+	require.Error(t, err)
+}
+
+func TestAuditScheduleUnmarshalRejectsInvalidCron(t *testing.T) {
+	var schedule AuditSchedule
+	err := json.Unmarshal([]byte(`{"cron":"not a cron","timezone":"UTC"}`), &schedule)
+	require.Error(t, err)
+}
+
+func TestAuditScheduleNextAuditDateDuration(t *testing.T) {
+	schedule := AuditSchedule{Duration: time.Hour}
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	next, err := schedule.NextAuditDate(after)
+	require.NoError(t, err)
+	require.Equal(t, after.Add(time.Hour), next)
+}
+
+func TestAuditScheduleNextAuditDateCronAcrossDST(t *testing.T) {
+	// America/New_York switches to daylight time at 2024-03-10 02:00 local.
+	// A 09:00 local cron schedule must land on 09:00 local both before and
+	// after the transition, even though the UTC offset changes.
+	schedule := AuditSchedule{Cron: "0 9 * * *", Timezone: "America/New_York"}
+
+	// 15:00 UTC on Mar 9 is 10:00 EST, already past that day's 09:00, so
+	// the next occurrence is 09:00 EDT on Mar 10.
+	before := time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC)
+	next, err := schedule.NextAuditDate(before)
+	require.NoError(t, err)
+	require.Equal(t, 9, next.Hour())
+	require.Equal(t, 10, next.Day())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// flexibleTimeLayouts are the text layouts ParseFlexibleTime tries, in
+// order, before falling back to the "in <duration>" and epoch-number
+// forms. RFC3339Nano is tried first since it's a superset of RFC3339 (it
+// also accepts inputs with no fractional seconds).
+var flexibleTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// epochMillisThreshold distinguishes epoch seconds from epoch
+// milliseconds in a bare JSON number: seconds-since-epoch for any date in
+// the next few centuries stays well under 1e12, while milliseconds for any
+// date after 2001-09-09 is above it.
+const epochMillisThreshold = 1e12
+
+// ParseFlexibleTime parses a JSON time value in any of several formats:
+// an RFC3339 or RFC3339Nano string, a bare "2006-01-02" date, a relative
+// "in <duration>" string (resolved against now), or a JSON number giving
+// epoch seconds or milliseconds. The returned time is always in UTC.
+//
+// It's used by Audit.UnmarshalJSON for NextAuditDate, and is exported so
+// any other edge that ingests operator-supplied audit dates can reuse the
+// same parsing rules.
+func ParseFlexibleTime(data []byte, now time.Time) (time.Time, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || string(trimmed) == "null" {
+		return time.Time{}, trace.BadParameter("missing time value")
+	}
+
+	if trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return time.Time{}, trace.Wrap(err)
+		}
+		return parseFlexibleTimeString(s, now)
+	}
+
+	var n float64
+	if err := json.Unmarshal(trimmed, &n); err != nil {
+		return time.Time{}, trace.BadParameter("time value %q is neither a string nor a number", trimmed)
+	}
+	return parseEpochNumber(n), nil
+}
+
+func parseFlexibleTimeString(s string, now time.Time) (time.Time, error) {
+	if rest, ok := strings.CutPrefix(s, "in "); ok {
+		if d, err := time.ParseDuration(rest); err == nil {
+			return now.Add(d).UTC(), nil
+		}
+	}
+
+	attempted := make([]string, 0, len(flexibleTimeLayouts)+1)
+	for _, layout := range flexibleTimeLayouts {
+		attempted = append(attempted, layout)
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	attempted = append(attempted, `"in <duration>"`)
+
+	return time.Time{}, trace.BadParameter(
+		"could not parse time %q using any supported format: tried %s", s, strings.Join(attempted, ", "))
+}
+
+func parseEpochNumber(n float64) time.Time {
+	if n > epochMillisThreshold || n < -epochMillisThreshold {
+		return time.UnixMilli(int64(n)).UTC()
+	}
+	return time.Unix(int64(n), 0).UTC()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesslist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlexibleTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data string
+		want time.Time
+	}{
+		{
+			name: "RFC3339",
+			data: `"2023-02-02T00:00:00Z"`,
+			want: time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "RFC3339Nano",
+			data: `"2023-02-02T00:00:00.123456789Z"`,
+			want: time.Date(2023, 2, 2, 0, 0, 0, 123456789, time.UTC),
+		},
+		{
+			name: "bare date",
+			data: `"2023-02-02"`,
+			want: time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "relative duration",
+			data: `"in 24h"`,
+			want: now.Add(24 * time.Hour),
+		},
+		{
+			name: "epoch seconds",
+			data: `1675296000`,
+			want: time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "epoch millis",
+			data: `1675296000000`,
+			want: time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFlexibleTime([]byte(tt.data), now)
+			require.NoError(t, err)
+			require.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+func TestParseFlexibleTimeMalformed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ``},
+		{name: "null", data: `null`},
+		{name: "garbage string", data: `"not a time"`},
+		{name: "garbage duration", data: `"in not-a-duration"`},
+		{name: "object", data: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFlexibleTime([]byte(tt.data), now)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseFlexibleTimeErrorListsAttemptedLayouts(t *testing.T) {
+	_, err := ParseFlexibleTime([]byte(`"not a time"`), time.Now())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), time.RFC3339)
+	require.Contains(t, err.Error(), "2006-01-02")
+	require.Contains(t, err.Error(), "in <duration>")
+}
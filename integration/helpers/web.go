@@ -155,6 +155,49 @@ func (w *WebClientPack) DoRequest(t *testing.T, method, endpoint string, payload
 // Raw websocket and HTTP response are returned.
 // "$site" in the endpoint is substituted by the current site.
 func (w *WebClientPack) OpenWebsocket(t *testing.T, endpoint string, params any) (*websocket.Conn, *http.Response, error) {
+	return w.OpenWebsocketWithOptions(t, endpoint, params, WebsocketOptions{})
+}
+
+const (
+	// DefaultWebsocketMaxMessageSize is the default cap on a single inbound
+	// websocket message, large enough for a chunk of a terminal recording or
+	// a tsh play frame without truncation.
+	DefaultWebsocketMaxMessageSize = 1 << 20 // 1 MiB
+
+	defaultWebsocketBufferSize = 4096
+)
+
+// WebsocketOptions configures the buffer sizes, maximum message size, and
+// compression negotiation used by OpenWebsocketWithOptions. The zero value
+// selects gorilla/websocket's defaults for buffer sizes plus
+// DefaultWebsocketMaxMessageSize for the max message size, with compression
+// left off.
+type WebsocketOptions struct {
+	// ReadBufferSize and WriteBufferSize size the dialer's I/O buffers. Zero
+	// selects gorilla/websocket's built-in default.
+	ReadBufferSize  int
+	WriteBufferSize int
+	// MaxMessageSize caps the size of a single inbound message (it's set as
+	// the connection's read limit); outbound messages are not limited.
+	// Zero selects DefaultWebsocketMaxMessageSize.
+	MaxMessageSize int64
+	// EnableCompression opts into permessage-deflate compression
+	// negotiation with the server.
+	EnableCompression bool
+}
+
+func (o WebsocketOptions) maxMessageSize() int64 {
+	if o.MaxMessageSize <= 0 {
+		return DefaultWebsocketMaxMessageSize
+	}
+	return o.MaxMessageSize
+}
+
+// OpenWebsocketWithOptions is like OpenWebsocket but allows the caller to
+// override the dialer's buffer sizes, the maximum message size, and whether
+// to negotiate permessage-deflate compression, so tests can exercise both
+// large-frame and compressed paths.
+func (w *WebClientPack) OpenWebsocketWithOptions(t *testing.T, endpoint string, params any, opts WebsocketOptions) (*websocket.Conn, *http.Response, error) {
 	path, err := url.JoinPath("v1", "webapi", strings.ReplaceAll(endpoint, "$site", w.clusterName))
 	require.NoError(t, err)
 
@@ -174,7 +217,11 @@ func (w *WebClientPack) OpenWebsocket(t *testing.T, endpoint string, params any)
 	q.Set(roundtrip.AccessTokenQueryParam, w.bearerToken)
 	u.RawQuery = q.Encode()
 
-	dialer := websocket.Dialer{}
+	dialer := websocket.Dialer{
+		ReadBufferSize:    opts.ReadBufferSize,
+		WriteBufferSize:   opts.WriteBufferSize,
+		EnableCompression: opts.EnableCompression,
+	}
 	dialer.TLSClientConfig = &tls.Config{
 		InsecureSkipVerify: true,
 	}
@@ -189,5 +236,11 @@ func (w *WebClientPack) OpenWebsocket(t *testing.T, endpoint string, params any)
 	header.Add("Cookie", cookie.String())
 
 	ws, resp, err := dialer.Dial(u.String(), header)
-	return ws, resp, trace.Wrap(err)
+	if err != nil {
+		return ws, resp, trace.Wrap(err)
+	}
+
+	ws.SetReadLimit(opts.maxMessageSize())
+
+	return ws, resp, nil
 }
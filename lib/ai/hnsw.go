@@ -0,0 +1,248 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/gravitational/teleport/lib/ai/embedding"
+)
+
+// HNSWRetriever is a [Retriever] backed by an in-memory Hierarchical
+// Navigable Small World graph. Unlike [SimpleRetriever], which scans every
+// embedding for each query, HNSWRetriever answers GetRelevant in roughly
+// O(log N) time by greedily descending a multi-layer proximity graph, which
+// matters once a cluster has tens of thousands of nodes/apps. SimpleRetriever
+// is kept around as the ground truth used to validate HNSWRetriever's
+// recall.
+type HNSWRetriever struct {
+	mu sync.RWMutex
+
+	// M is the number of bidirectional links created per new element at
+	// every layer it's inserted into.
+	M int
+	// Mmax is the max number of neighbors per node at layers above 0.
+	Mmax int
+	// Mmax0 is the max number of neighbors per node at layer 0; it's
+	// conventionally 2*M since layer 0 holds every element.
+	Mmax0 int
+	// EfConstruction controls the candidate list size used while inserting;
+	// larger values trade insertion cost for graph quality.
+	EfConstruction int
+	// EfSearch is the default candidate list size used for queries.
+	EfSearch int
+
+	mL float64
+
+	rand *rand.Rand
+
+	nodes       map[string]*hnswNode
+	entryPoint  string
+	topLayer    int
+}
+
+type hnswNode struct {
+	id        string
+	embedding *embedding.Embedding
+	// neighbors[layer] holds this node's neighbor ids at that layer.
+	neighbors [][]string
+}
+
+// HNSWConfig holds the tunables for [NewHNSWRetriever]; zero values select
+// the defaults recommended by the original HNSW paper for small-to-medium
+// (<1M vector) datasets.
+type HNSWConfig struct {
+	M              int
+	EfConstruction int
+	EfSearch       int
+}
+
+func (c *HNSWConfig) checkAndSetDefaults() {
+	if c.M <= 0 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+}
+
+// NewHNSWRetriever creates an empty [HNSWRetriever] with the given tunables.
+func NewHNSWRetriever(cfg HNSWConfig) *HNSWRetriever {
+	cfg.checkAndSetDefaults()
+
+	return &HNSWRetriever{
+		M:              cfg.M,
+		Mmax:           cfg.M,
+		Mmax0:          cfg.M * 2,
+		EfConstruction: cfg.EfConstruction,
+		EfSearch:       cfg.EfSearch,
+		mL:             1 / math.Log(float64(cfg.M)),
+		rand:           rand.New(rand.NewSource(1)),
+		nodes:          make(map[string]*hnswNode),
+		topLayer:       -1,
+	}
+}
+
+// Insert implements [Retriever].
+func (h *HNSWRetriever) Insert(id string, e *embedding.Embedding) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	level := h.randomLevel()
+	node := &hnswNode{
+		id:        id,
+		embedding: e,
+		neighbors: make([][]string, level+1),
+	}
+	h.nodes[id] = node
+
+	if h.entryPoint == "" {
+		h.entryPoint = id
+		h.topLayer = level
+		return
+	}
+
+	ep := h.entryPoint
+	// descend greedily from the top layer down to level+1, keeping only the
+	// single closest element as the entry point for the next layer down.
+	for layer := h.topLayer; layer > level; layer-- {
+		ep = h.searchLayer(e.Vector, ep, 1, layer, nil)[0].id
+	}
+
+	for layer := min(level, h.topLayer); layer >= 0; layer-- {
+		candidates := h.searchLayer(e.Vector, ep, h.EfConstruction, layer, nil)
+		mmax := h.Mmax
+		if layer == 0 {
+			mmax = h.Mmax0
+		}
+
+		selected := h.selectNeighborsHeuristic(e.Vector, candidates, h.M)
+		node.neighbors[layer] = selected
+
+		for _, nid := range selected {
+			neighbor := h.nodes[nid]
+			neighbor.ensureLayer(layer)
+			neighbor.neighbors[layer] = append(neighbor.neighbors[layer], id)
+			if len(neighbor.neighbors[layer]) > mmax {
+				neighbor.neighbors[layer] = h.selectNeighborsHeuristic(
+					neighbor.embedding.Vector, h.candidatesFromIDs(neighbor.embedding.Vector, neighbor.neighbors[layer]), mmax)
+			}
+		}
+
+		if len(candidates) > 0 {
+			ep = candidates[0].id
+		}
+	}
+
+	if level > h.topLayer {
+		h.topLayer = level
+		h.entryPoint = id
+	}
+}
+
+// Remove deletes id from the graph, unlinking it from every neighbor that
+// referenced it.
+func (h *HNSWRetriever) Remove(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node, ok := h.nodes[id]
+	if !ok {
+		return
+	}
+	delete(h.nodes, id)
+
+	for layer, neighbors := range node.neighbors {
+		for _, nid := range neighbors {
+			if neighbor, ok := h.nodes[nid]; ok && layer < len(neighbor.neighbors) {
+				neighbor.neighbors[layer] = removeID(neighbor.neighbors[layer], id)
+			}
+		}
+	}
+
+	if h.entryPoint == id {
+		h.entryPoint = ""
+		h.topLayer = -1
+		for otherID, other := range h.nodes {
+			if len(other.neighbors)-1 > h.topLayer {
+				h.topLayer = len(other.neighbors) - 1
+				h.entryPoint = otherID
+			}
+		}
+	}
+}
+
+// GetRelevant implements [Retriever]. It descends the graph from the entry
+// point to layer 0 and returns the top k closest embeddings that pass
+// filter; filtered-out ids are kept out of the result set during expansion
+// (not just dropped at the end) while still being traversed, so recall isn't
+// destroyed by a restrictive filter.
+func (h *HNSWRetriever) GetRelevant(query *embedding.Embedding, k int, filter func(id string, embedding *embedding.Embedding) bool) []*embedding.Embedding {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entryPoint == "" {
+		return nil
+	}
+
+	ep := h.entryPoint
+	for layer := h.topLayer; layer > 0; layer-- {
+		ep = h.searchLayer(query.Vector, ep, 1, layer, nil)[0].id
+	}
+
+	ef := h.EfSearch
+	if k > ef {
+		ef = k
+	}
+
+	candidates := h.searchLayer(query.Vector, ep, ef, 0, filter)
+
+	results := make([]*embedding.Embedding, 0, min(k, len(candidates)))
+	for i := 0; i < len(candidates) && i < k; i++ {
+		e := candidates[i].node.embedding
+		e.SimilarityScore = candidates[i].similarity
+		results = append(results, e)
+	}
+	return results
+}
+
+// randomLevel samples an insertion level following the exponentially
+// decaying level distribution used by HNSW: l = floor(-ln(U(0,1)) * mL).
+func (h *HNSWRetriever) randomLevel() int {
+	return int(math.Floor(-math.Log(h.rand.Float64()) * h.mL))
+}
+
+func (n *hnswNode) ensureLayer(layer int) {
+	for len(n.neighbors) <= layer {
+		n.neighbors = append(n.neighbors, nil)
+	}
+}
+
+func removeID(ids []string, target string) []string {
+	out := ids[:0]
+	for _, id := range ids {
+		if id != target {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ai
+
+import (
+	"container/heap"
+
+	"github.com/gravitational/teleport/lib/ai/embedding"
+)
+
+// hnswCandidate is a node paired with its similarity to the query vector
+// that's currently being searched for.
+type hnswCandidate struct {
+	id         string
+	node       *hnswNode
+	similarity float64
+}
+
+// hnswCandidateHeap is a min-heap on similarity, used to track the working
+// result set during search-layer: the least similar candidate currently kept
+// is always at the root so it can be evicted in O(log ef) once the result
+// set is full.
+type hnswCandidateHeap []hnswCandidate
+
+func (h hnswCandidateHeap) Len() int            { return len(h) }
+func (h hnswCandidateHeap) Less(i, j int) bool  { return h[i].similarity < h[j].similarity }
+func (h hnswCandidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hnswCandidateHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *hnswCandidateHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// hnswFrontierHeap is a max-heap on similarity, used for the exploration
+// frontier during search-layer: the most similar unexpanded candidate is
+// always at the root so best-first search expands it next.
+type hnswFrontierHeap []hnswCandidate
+
+func (h hnswFrontierHeap) Len() int            { return len(h) }
+func (h hnswFrontierHeap) Less(i, j int) bool  { return h[i].similarity > h[j].similarity }
+func (h hnswFrontierHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hnswFrontierHeap) Push(x interface{}) { *h = append(*h, x.(hnswCandidate)) }
+func (h *hnswFrontierHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// searchLayer runs the best-first search described by the HNSW paper at a
+// single layer: starting from entryID, it repeatedly expands the closest
+// unvisited candidate until none of its neighbors is closer to query than
+// the current worst kept result, and returns up to ef results sorted by
+// descending similarity. If filter is non-nil, filtered-out ids are still
+// traversed (so the graph stays connected) but never returned.
+func (h *HNSWRetriever) searchLayer(query embedding.Vector64, entryID string, ef int, layer int, filter func(id string, embedding *embedding.Embedding) bool) []hnswCandidate {
+	entry := h.nodes[entryID]
+	entrySim := cosineSimilarity(query, entry.embedding.Vector)
+
+	visited := map[string]bool{entryID: true}
+
+	// candidates is a max-first exploration frontier; results is the
+	// min-similarity-first kept set capped at ef, so the worst kept result
+	// can be evicted in O(log ef).
+	candidates := &hnswFrontierHeap{{id: entryID, node: entry, similarity: entrySim}}
+	results := &hnswCandidateHeap{}
+	if filter == nil || filter(entryID, entry.embedding) {
+		*results = append(*results, (*candidates)[0])
+	}
+
+	for candidates.Len() > 0 {
+		best := popMostSimilar(candidates)
+		if results.Len() >= ef {
+			worst := (*results)[0]
+			if best.similarity < worst.similarity {
+				break
+			}
+		}
+
+		if layer >= len(best.node.neighbors) {
+			continue
+		}
+
+		for _, nid := range best.node.neighbors[layer] {
+			if visited[nid] {
+				continue
+			}
+			visited[nid] = true
+
+			neighbor := h.nodes[nid]
+			if neighbor == nil {
+				continue
+			}
+			sim := cosineSimilarity(query, neighbor.embedding.Vector)
+
+			heap.Push(candidates, hnswCandidate{id: nid, node: neighbor, similarity: sim})
+
+			if filter != nil && !filter(nid, neighbor.embedding) {
+				continue
+			}
+
+			if results.Len() < ef {
+				heap.Push(results, hnswCandidate{id: nid, node: neighbor, similarity: sim})
+			} else if sim > (*results)[0].similarity {
+				heap.Pop(results)
+				heap.Push(results, hnswCandidate{id: nid, node: neighbor, similarity: sim})
+			}
+		}
+	}
+
+	return sortedDescending(*results)
+}
+
+// popMostSimilar pops the candidate with the highest similarity from the
+// exploration frontier. hnswFrontierHeap is a max-heap, so heap.Pop returns
+// the most similar element directly.
+func popMostSimilar(h *hnswFrontierHeap) hnswCandidate {
+	return heap.Pop(h).(hnswCandidate)
+}
+
+func sortedDescending(candidates []hnswCandidate) []hnswCandidate {
+	h := hnswFrontierHeap(candidates)
+	heap.Init(&h)
+	out := make([]hnswCandidate, 0, len(h))
+	for h.Len() > 0 {
+		out = append(out, heap.Pop(&h).(hnswCandidate))
+	}
+	return out
+}
+
+// selectNeighborsHeuristic implements the HNSW "heuristic" neighbor
+// selection: candidates are considered in decreasing order of similarity to
+// query, and a candidate is kept only if it's closer to query than to every
+// neighbor already selected. This favors diverse directions over simply
+// taking the m closest candidates, which keeps the graph well connected.
+func (h *HNSWRetriever) selectNeighborsHeuristic(query embedding.Vector64, candidates []hnswCandidate, m int) []string {
+	selected := make([]hnswCandidate, 0, m)
+
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+
+		keep := true
+		for _, s := range selected {
+			if cosineSimilarity(c.node.embedding.Vector, s.node.embedding.Vector) > c.similarity {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c)
+		}
+	}
+
+	ids := make([]string, len(selected))
+	for i, c := range selected {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+// candidatesFromIDs rebuilds candidates for ids, scoring each against query
+// so selectNeighborsHeuristic can re-prune an overflowing neighbor list.
+func (h *HNSWRetriever) candidatesFromIDs(query embedding.Vector64, ids []string) []hnswCandidate {
+	out := make([]hnswCandidate, 0, len(ids))
+	for _, id := range ids {
+		node := h.nodes[id]
+		if node == nil {
+			continue
+		}
+		out = append(out, hnswCandidate{id: id, node: node, similarity: cosineSimilarity(query, node.embedding.Vector)})
+	}
+	return sortedDescending(out)
+}
+
+// cosineSimilarity returns the dot product of two already-normalized
+// vectors, which is equivalent to cosine similarity and matches the
+// convention used by [SimpleRetriever].
+func cosineSimilarity(a, b embedding.Vector64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
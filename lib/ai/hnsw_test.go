@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ai
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/ai/embedding"
+)
+
+func TestHNSWRetriever_GetRelevant(t *testing.T) {
+	t.Parallel()
+
+	randGen := rand.New(rand.NewSource(42))
+	generateVector := func() embedding.Vector64 {
+		const testVectorDimension = 100
+		vec := make(embedding.Vector64, testVectorDimension)
+		for i := 0; i < testVectorDimension; i++ {
+			vec[i] = randGen.Float64()
+		}
+		return normalize(vec)
+	}
+
+	const testEmbeddingsSize = 500
+	points := make([]*embedding.Embedding, testEmbeddingsSize)
+	for i := 0; i < testEmbeddingsSize; i++ {
+		points[i] = embedding.NewEmbedding(types.KindNode, strconv.Itoa(i), generateVector(), [32]byte{})
+	}
+
+	query := embedding.NewEmbedding(types.KindNode, "query", generateVector(), [32]byte{})
+
+	simple := NewSimpleRetriever()
+	hnsw := NewHNSWRetriever(HNSWConfig{})
+	for _, point := range points {
+		simple.Insert(point.GetName(), point)
+		hnsw.Insert(point.GetName(), point)
+	}
+
+	const k = 10
+	noopFilter := func(id string, e *embedding.Embedding) bool { return true }
+	want := simple.GetRelevant(query, k, noopFilter)
+	got := hnsw.GetRelevant(query, k, noopFilter)
+
+	require.Len(t, got, k)
+
+	// HNSW is approximate, so we check recall@k against brute force rather
+	// than requiring an identical ordering.
+	wantIDs := make(map[string]bool, len(want))
+	for _, e := range want {
+		wantIDs[e.GetName()] = true
+	}
+
+	hits := 0
+	for _, e := range got {
+		if wantIDs[e.GetName()] {
+			hits++
+		}
+	}
+
+	recall := float64(hits) / float64(k)
+	require.GreaterOrEqual(t, recall, 0.8, "recall@%d should be at least 0.8, got %f", k, recall)
+}
+
+func TestHNSWRetriever_FilterSkipsDuringExpansion(t *testing.T) {
+	t.Parallel()
+
+	randGen := rand.New(rand.NewSource(7))
+	generateVector := func() embedding.Vector64 {
+		vec := make(embedding.Vector64, 16)
+		for i := range vec {
+			vec[i] = randGen.Float64()
+		}
+		return normalize(vec)
+	}
+
+	hnsw := NewHNSWRetriever(HNSWConfig{M: 4, EfConstruction: 32, EfSearch: 16})
+
+	const size = 100
+	for i := 0; i < size; i++ {
+		e := embedding.NewEmbedding(types.KindNode, strconv.Itoa(i), generateVector(), [32]byte{})
+		hnsw.Insert(e.GetName(), e)
+	}
+
+	query := embedding.NewEmbedding(types.KindNode, "query", generateVector(), [32]byte{})
+
+	// Only even-numbered ids are allowed through the filter; odd ids must
+	// still be traversable so the search can reach every even id.
+	evenOnly := func(id string, e *embedding.Embedding) bool {
+		n, err := strconv.Atoi(strings.TrimPrefix(id, types.KindNode+"/"))
+		require.NoError(t, err)
+		return n%2 == 0
+	}
+
+	docs := hnsw.GetRelevant(query, 5, evenOnly)
+	require.Len(t, docs, 5)
+	for _, d := range docs {
+		require.True(t, evenOnly(d.GetName(), d))
+	}
+}
+
+func benchmarkRetrieverGetRelevant(b *testing.B, n int, newRetriever func() Retriever) {
+	randGen := rand.New(rand.NewSource(42))
+	generateVector := func() embedding.Vector64 {
+		vec := make(embedding.Vector64, 100)
+		for i := range vec {
+			vec[i] = randGen.Float64()
+		}
+		return normalize(vec)
+	}
+
+	retriever := newRetriever()
+	for i := 0; i < n; i++ {
+		e := embedding.NewEmbedding(types.KindNode, strconv.Itoa(i), generateVector(), [32]byte{})
+		retriever.Insert(e.GetName(), e)
+	}
+	query := embedding.NewEmbedding(types.KindNode, "query", generateVector(), [32]byte{})
+	noopFilter := func(id string, e *embedding.Embedding) bool { return true }
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		retriever.GetRelevant(query, 10, noopFilter)
+	}
+}
+
+func BenchmarkSimpleRetriever_GetRelevant_10k(b *testing.B) {
+	benchmarkRetrieverGetRelevant(b, 10_000, func() Retriever { return NewSimpleRetriever() })
+}
+
+func BenchmarkHNSWRetriever_GetRelevant_10k(b *testing.B) {
+	benchmarkRetrieverGetRelevant(b, 10_000, func() Retriever { return NewHNSWRetriever(HNSWConfig{}) })
+}
+
+func BenchmarkSimpleRetriever_GetRelevant_100k(b *testing.B) {
+	benchmarkRetrieverGetRelevant(b, 100_000, func() Retriever { return NewSimpleRetriever() })
+}
+
+func BenchmarkHNSWRetriever_GetRelevant_100k(b *testing.B) {
+	benchmarkRetrieverGetRelevant(b, 100_000, func() Retriever { return NewHNSWRetriever(HNSWConfig{}) })
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package output defines the shapes the assistant model's completions are
+// parsed into, and the parsing helpers tool ParseInput implementations use
+// to turn the model's raw JSON text into them.
+package output
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// Label is a single node label match, as used by CompletionCommand's
+// legacy Labels field: a node matches if it carries any of the given
+// labels.
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// CompletionCommand is the parsed form of a CommandExecutionTool
+// completion: the command to run and the node set to run it on.
+type CompletionCommand struct {
+	Command string   `json:"command"`
+	Nodes   []string `json:"nodes"`
+	Labels  []Label  `json:"labels"`
+	// Selector is the structured matchLabels/matchExpressions/anyOf/allOf/
+	// not expression tree node targeting lowers into. Nodes and Labels are
+	// legacy sugar for simple cases; see Lower.
+	Selector *Selector `json:"selector,omitempty"`
+	// DryRun, when true, tells the assistant loop to resolve and return
+	// the target node set instead of emitting an executable SSH plan.
+	DryRun bool `json:"dry_run"`
+}
+
+// InvalidOutputError is returned by ParseInput implementations when the
+// model's output is well-formed JSON but fails a tool's own validation
+// rules (as opposed to a JSON syntax error).
+type InvalidOutputError struct {
+	reason string
+	detail string
+}
+
+// NewInvalidOutputError returns an InvalidOutputError, reporting reason to
+// the caller and detail to the model so it can retry.
+func NewInvalidOutputError(reason, detail string) error {
+	return &InvalidOutputError{reason: reason, detail: detail}
+}
+
+// Error implements error.
+func (e *InvalidOutputError) Error() string {
+	return e.reason
+}
+
+// Detail is shown back to the model so it can correct its next attempt.
+func (e *InvalidOutputError) Detail() string {
+	return e.detail
+}
+
+// ParseJSONFromModel unmarshals the model's raw completion text into T.
+// Models sometimes wrap JSON in a ```json fenced code block; that fencing
+// is stripped before unmarshaling.
+func ParseJSONFromModel[T any](input string) (T, error) {
+	var out T
+
+	trimmed := strings.TrimSpace(input)
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	trimmed = strings.TrimSpace(trimmed)
+
+	if err := json.Unmarshal([]byte(trimmed), &out); err != nil {
+		return out, trace.Wrap(err, "parsing model output as JSON")
+	}
+	return out, nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package output
+
+// LabelSelectorOperator is the comparison a LabelSelectorRequirement
+// applies, mirroring Kubernetes' label selector requirement operators.
+type LabelSelectorOperator string
+
+const (
+	SelectorOpIn           LabelSelectorOperator = "In"
+	SelectorOpNotIn        LabelSelectorOperator = "NotIn"
+	SelectorOpExists       LabelSelectorOperator = "Exists"
+	SelectorOpDoesNotExist LabelSelectorOperator = "DoesNotExist"
+)
+
+// LabelSelectorRequirement is a single matchExpressions entry: a node
+// matches if its value for Key satisfies Operator against Values.
+type LabelSelectorRequirement struct {
+	Key      string                `json:"key"`
+	Operator LabelSelectorOperator `json:"operator"`
+	Values   []string              `json:"values,omitempty"`
+}
+
+// Selector is a Kubernetes-style node selector expression: MatchLabels and
+// MatchExpressions are AND-ed leaf conditions, and AnyOf/AllOf/Not combine
+// child selectors into a boolean tree, so the model can express things
+// like "all prod db nodes except those in us-west-2".
+type Selector struct {
+	// MatchLabels requires a node to carry every key/value pair exactly.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	// MatchExpressions requires a node to satisfy every requirement.
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+	// AnyOf requires a node to match at least one child selector.
+	AnyOf []Selector `json:"anyOf,omitempty"`
+	// AllOf requires a node to match every child selector.
+	AllOf []Selector `json:"allOf,omitempty"`
+	// Not requires a node to not match the child selector.
+	Not *Selector `json:"not,omitempty"`
+}
+
+// maxSelectorDepth bounds how deeply anyOf/allOf/not may nest. Selector
+// trees are decoded fresh from JSON each time, so they can't literally
+// contain a pointer cycle; this catches the equivalent failure mode, a
+// runaway or adversarially deep tree, before it blows the stack during
+// validation or matching.
+const maxSelectorDepth = 16
+
+// LegacyNodeNameKey is the reserved matchExpressions key the legacy Nodes
+// field lowers into (see CompletionCommand.Lower): it matches a node's
+// name rather than one of its labels. Selector compilers outside this
+// package (see tools.matchesSelector) must special-case it the same way.
+const LegacyNodeNameKey = "__node_name__"
+
+// ValidateSelector checks that sel is well-formed: every operator is one
+// of the known ones, In/NotIn requirements carry at least one value, no
+// selector node is vacuously empty, and the tree doesn't nest deeper than
+// maxSelectorDepth.
+func ValidateSelector(sel *Selector) error {
+	return validateSelector(sel, 0)
+}
+
+func validateSelector(sel *Selector, depth int) error {
+	if sel == nil {
+		return nil
+	}
+	if depth > maxSelectorDepth {
+		return NewInvalidOutputError(
+			"command execution: selector nested too deeply",
+			"selector anyOf/allOf/not may not nest more than 16 levels deep",
+		)
+	}
+	if isEmptySelector(sel) {
+		return NewInvalidOutputError(
+			"command execution: empty selector",
+			"selector must specify matchLabels, matchExpressions, anyOf, allOf, or not; an empty selector would silently match every node",
+		)
+	}
+
+	for _, req := range sel.MatchExpressions {
+		if err := validateRequirement(req); err != nil {
+			return err
+		}
+	}
+	for i := range sel.AnyOf {
+		if err := validateSelector(&sel.AnyOf[i], depth+1); err != nil {
+			return err
+		}
+	}
+	for i := range sel.AllOf {
+		if err := validateSelector(&sel.AllOf[i], depth+1); err != nil {
+			return err
+		}
+	}
+	if sel.Not != nil {
+		if err := validateSelector(sel.Not, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEmptySelector reports whether sel has no conditions at all, which
+// would otherwise match every node without the caller meaning it to.
+func isEmptySelector(sel *Selector) bool {
+	return len(sel.MatchLabels) == 0 &&
+		len(sel.MatchExpressions) == 0 &&
+		len(sel.AnyOf) == 0 &&
+		len(sel.AllOf) == 0 &&
+		sel.Not == nil
+}
+
+func validateRequirement(req LabelSelectorRequirement) error {
+	if req.Key == "" {
+		return NewInvalidOutputError(
+			"command execution: empty selector key",
+			"matchExpressions entries must specify a key",
+		)
+	}
+
+	switch req.Operator {
+	case SelectorOpIn, SelectorOpNotIn:
+		if len(req.Values) == 0 {
+			return NewInvalidOutputError(
+				"command execution: empty selector values",
+				string(req.Operator)+" requires at least one value",
+			)
+		}
+	case SelectorOpExists, SelectorOpDoesNotExist:
+		// No values expected; any that were sent are simply ignored.
+	default:
+		return NewInvalidOutputError(
+			"command execution: unknown selector operator",
+			"operator must be one of In, NotIn, Exists, DoesNotExist",
+		)
+	}
+
+	return nil
+}
+
+// Lower returns cmd's selector, synthesizing one from the legacy Nodes and
+// Labels fields when no selector was given explicitly. Nodes become an In
+// requirement on LegacyNodeNameKey, each Label becomes its own matchLabels
+// leaf, and the two are OR-ed together via anyOf, preserving the legacy
+// "matches any of these names or any of these labels" semantics.
+func (cmd *CompletionCommand) Lower() *Selector {
+	if cmd.Selector != nil {
+		return cmd.Selector
+	}
+
+	var clauses []Selector
+	if len(cmd.Nodes) > 0 {
+		clauses = append(clauses, Selector{
+			MatchExpressions: []LabelSelectorRequirement{{
+				Key:      LegacyNodeNameKey,
+				Operator: SelectorOpIn,
+				Values:   cmd.Nodes,
+			}},
+		})
+	}
+	for _, label := range cmd.Labels {
+		clauses = append(clauses, Selector{MatchLabels: map[string]string{label.Key: label.Value}})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil
+	case 1:
+		return &clauses[0]
+	default:
+		return &Selector{AnyOf: clauses}
+	}
+}
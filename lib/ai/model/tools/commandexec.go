@@ -18,6 +18,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/gravitational/trace"
@@ -39,13 +40,42 @@ The input must be a JSON object with the following schema:
 {
 	"command": string, \\ The command to execute
 	"nodes": []string, \\ Execute a command on all nodes that have the given node names
-	"labels": []{"key": string, "value": string} \\ Execute a command on all nodes that has at least one of the labels
+	"labels": []{"key": string, "value": string}, \\ Execute a command on all nodes that has at least one of the labels
+	"selector": { \\ Optional. A structured node selector, preferred over nodes/labels for anything beyond a flat OR of labels
+		"matchLabels": {string: string}, \\ Node must carry every key/value pair
+		"matchExpressions": []{"key": string, "operator": "In"|"NotIn"|"Exists"|"DoesNotExist", "values": []string}, \\ Node must satisfy every requirement
+		"anyOf": []selector, \\ Node must match at least one child selector
+		"allOf": []selector, \\ Node must match every child selector
+		"not": selector \\ Node must not match the child selector
+	},
+	"dry_run": bool \\ Optional. If true, resolve and return the target node set instead of executing the command
 }
 %v
 `, "```", "```")
 }
 
-func (c *CommandExecutionTool) Run(_ context.Context, _ *ToolContext, _ string) (string, error) {
+func (c *CommandExecutionTool) Run(ctx context.Context, toolCtx *ToolContext, input string) (string, error) {
+	cmd, err := c.ParseInput(input)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	// A dry run doesn't execute anything, so unlike the branch below it
+	// doesn't need to break the thought loop for user confirmation; it can
+	// just resolve the target node set and return it like any other tool.
+	if cmd.DryRun {
+		result, err := ResolveDryRun(ctx, toolCtx, cmd)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+
+		out, err := json.Marshal(result)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return string(out), nil
+	}
+
 	// This is stubbed because CommandExecutionTool is handled specially.
 	// This is because execution of this tool breaks the loop and returns a command suggestion to the user.
 	// It is still handled as a tool because testing has shown that the LLM behaves better when it is treated as a tool.
@@ -69,12 +99,16 @@ func (*CommandExecutionTool) ParseInput(input string) (*modeloutput.CompletionCo
 		)
 	}
 
-	if len(output.Nodes) == 0 && len(output.Labels) == 0 {
+	if len(output.Nodes) == 0 && len(output.Labels) == 0 && output.Selector == nil {
 		return nil, modeloutput.NewInvalidOutputError(
-			"command execution: missing nodes or labels",
-			"at least one node or label must be specified",
+			"command execution: missing nodes, labels, or selector",
+			"at least one node, label, or selector must be specified",
 		)
 	}
 
+	if err := modeloutput.ValidateSelector(output.Selector); err != nil {
+		return nil, err
+	}
+
 	return &output, nil
 }
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandExecutionToolParseInput(t *testing.T) {
+	tool := &CommandExecutionTool{}
+
+	output, err := tool.ParseInput(`{"command": "uptime", "nodes": ["node1"], "dry_run": true}`)
+	require.NoError(t, err)
+	require.Equal(t, "uptime", output.Command)
+	require.True(t, output.DryRun)
+
+	_, err = tool.ParseInput(`{"command": "uptime", "nodes": ["node1"], "dry_run": "yes"}`)
+	require.Error(t, err)
+
+	_, err = tool.ParseInput(`{"nodes": ["node1"]}`)
+	require.Error(t, err)
+
+	_, err = tool.ParseInput(`{"command": "uptime"}`)
+	require.Error(t, err)
+}
+
+func TestCommandExecutionToolParseInputSelector(t *testing.T) {
+	tool := &CommandExecutionTool{}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "matchLabels",
+			input: `{"command": "uptime", "selector": {"matchLabels": {"env": "prod"}}}`,
+		},
+		{
+			name: "matchExpressions with In",
+			input: `{"command": "uptime", "selector": {"matchExpressions": [
+				{"key": "env", "operator": "In", "values": ["prod", "staging"]}
+			]}}`,
+		},
+		{
+			name: "anyOf/allOf/not tree",
+			input: `{"command": "uptime", "selector": {"allOf": [
+				{"matchLabels": {"env": "prod"}},
+				{"not": {"matchLabels": {"region": "us-west-2"}}}
+			]}}`,
+		},
+		{
+			name:    "empty selector object is rejected, not treated as match-everything",
+			input:   `{"command": "uptime", "selector": {}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown operator",
+			input:   `{"command": "uptime", "selector": {"matchExpressions": [{"key": "env", "operator": "Equals", "values": ["prod"]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "In with no values",
+			input:   `{"command": "uptime", "selector": {"matchExpressions": [{"key": "env", "operator": "In"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "NotIn with no values",
+			input:   `{"command": "uptime", "selector": {"matchExpressions": [{"key": "env", "operator": "NotIn", "values": []}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Exists needs no values",
+			input:   `{"command": "uptime", "selector": {"matchExpressions": [{"key": "env", "operator": "Exists"}]}}`,
+			wantErr: false,
+		},
+		{
+			name:    "empty requirement key",
+			input:   `{"command": "uptime", "selector": {"matchExpressions": [{"key": "", "operator": "Exists"}]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.ParseInput(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCommandExecutionToolRunDryRun(t *testing.T) {
+	tool := &CommandExecutionTool{}
+	toolCtx := &ToolContext{NodeInventory: fakeNodeInventory{nodes: []Node{
+		{Name: "node1", Labels: map[string]string{"env": "prod"}, Accessible: true},
+		{Name: "node2", Labels: map[string]string{"env": "prod"}, Accessible: false},
+	}}}
+
+	out, err := tool.Run(context.Background(), toolCtx, `{"command": "uptime", "labels": [{"key": "env", "value": "prod"}], "dry_run": true}`)
+	require.NoError(t, err)
+
+	var result DryRunResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, "uptime", result.Command)
+	require.Equal(t, []string{"node1"}, result.Hosts)
+	require.Equal(t, []string{"node2"}, result.InaccessibleHosts)
+}
+
+func TestCommandExecutionToolRunWithoutDryRun(t *testing.T) {
+	tool := &CommandExecutionTool{}
+
+	_, err := tool.Run(context.Background(), &ToolContext{}, `{"command": "uptime", "nodes": ["node1"]}`)
+	require.True(t, trace.IsNotImplemented(err))
+}
+
+func TestCompletionCommandLowerEquivalence(t *testing.T) {
+	nodes := []Node{
+		{Name: "node1", Labels: map[string]string{"env": "prod"}, Accessible: true},
+		{Name: "node2", Labels: map[string]string{"env": "staging"}, Accessible: true},
+		{Name: "node3", Labels: map[string]string{"env": "prod"}, Accessible: true},
+	}
+
+	tool := &CommandExecutionTool{}
+
+	legacy, err := tool.ParseInput(`{"command": "uptime", "nodes": ["node2"], "labels": [{"key": "env", "value": "prod"}]}`)
+	require.NoError(t, err)
+
+	explicit, err := tool.ParseInput(`{"command": "uptime", "selector": {"anyOf": [
+		{"matchExpressions": [{"key": "__node_name__", "operator": "In", "values": ["node2"]}]},
+		{"matchLabels": {"env": "prod"}}
+	]}}`)
+	require.NoError(t, err)
+
+	legacySel := legacy.Lower()
+	explicitSel := explicit.Lower()
+
+	for _, node := range nodes {
+		require.Equal(t, matchesSelector(explicitSel, node), matchesSelector(legacySel, node), "node %s", node.Name)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+
+	modeloutput "github.com/gravitational/teleport/lib/ai/model/output"
+)
+
+// DryRunResult is what the assistant loop returns to the UI instead of an
+// executable SSH plan when CompletionCommand.DryRun is set: the concrete
+// node set the command would have run on, split by whether the requester
+// can actually reach each one.
+type DryRunResult struct {
+	// Command is the command that would have run.
+	Command string
+	// Hosts are the matched nodes the requester can reach.
+	Hosts []string
+	// InaccessibleHosts are matched nodes the requester lacks RBAC access
+	// to, surfaced so they can request access rather than being silently
+	// dropped from the plan.
+	InaccessibleHosts []string
+	// FanOut is len(Hosts), called out explicitly so callers don't have to
+	// infer blast radius from a slice length.
+	FanOut int
+}
+
+// ResolveDryRun expands cmd's node/label selection against the current
+// node inventory and reports what would happen, without producing
+// anything the execution path could act on.
+func ResolveDryRun(ctx context.Context, toolCtx *ToolContext, cmd *modeloutput.CompletionCommand) (*DryRunResult, error) {
+	if toolCtx == nil || toolCtx.NodeInventory == nil {
+		return nil, trace.BadParameter("dry run requires a node inventory")
+	}
+
+	nodes, err := toolCtx.NodeInventory.ListNodes(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	selector := cmd.Lower()
+	result := &DryRunResult{Command: cmd.Command}
+	for _, node := range nodes {
+		if !matchesSelector(selector, node) {
+			continue
+		}
+		if node.Accessible {
+			result.Hosts = append(result.Hosts, node.Name)
+		} else {
+			result.InaccessibleHosts = append(result.InaccessibleHosts, node.Name)
+		}
+	}
+	result.FanOut = len(result.Hosts)
+
+	return result, nil
+}
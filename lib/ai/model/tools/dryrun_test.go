@@ -0,0 +1,60 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	modeloutput "github.com/gravitational/teleport/lib/ai/model/output"
+)
+
+type fakeNodeInventory struct {
+	nodes []Node
+}
+
+func (f fakeNodeInventory) ListNodes(context.Context) ([]Node, error) {
+	return f.nodes, nil
+}
+
+func TestResolveDryRun(t *testing.T) {
+	inventory := fakeNodeInventory{nodes: []Node{
+		{Name: "node1", Labels: map[string]string{"env": "prod"}, Accessible: true},
+		{Name: "node2", Labels: map[string]string{"env": "prod"}, Accessible: false},
+		{Name: "node3", Labels: map[string]string{"env": "staging"}, Accessible: true},
+	}}
+	toolCtx := &ToolContext{NodeInventory: inventory}
+
+	cmd := &modeloutput.CompletionCommand{
+		Command: "uptime",
+		Labels:  []modeloutput.Label{{Key: "env", Value: "prod"}},
+	}
+
+	result, err := ResolveDryRun(context.Background(), toolCtx, cmd)
+	require.NoError(t, err)
+	require.Equal(t, "uptime", result.Command)
+	require.Equal(t, []string{"node1"}, result.Hosts)
+	require.Equal(t, []string{"node2"}, result.InaccessibleHosts)
+	require.Equal(t, 1, result.FanOut)
+}
+
+func TestResolveDryRunRequiresInventory(t *testing.T) {
+	_, err := ResolveDryRun(context.Background(), &ToolContext{}, &modeloutput.CompletionCommand{})
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import modeloutput "github.com/gravitational/teleport/lib/ai/model/output"
+
+// matchesSelector compiles sel into the node-matching predicate used by
+// ResolveDryRun: every call walks the tree
+// fresh rather than precompiling it, since a selector is only ever
+// evaluated against a single command's node inventory.
+func matchesSelector(sel *modeloutput.Selector, node Node) bool {
+	if sel == nil {
+		return false
+	}
+
+	for key, value := range sel.MatchLabels {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+
+	for _, req := range sel.MatchExpressions {
+		if !matchesRequirement(req, node) {
+			return false
+		}
+	}
+
+	for i := range sel.AllOf {
+		if !matchesSelector(&sel.AllOf[i], node) {
+			return false
+		}
+	}
+
+	if len(sel.AnyOf) > 0 {
+		matched := false
+		for i := range sel.AnyOf {
+			if matchesSelector(&sel.AnyOf[i], node) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if sel.Not != nil && matchesSelector(sel.Not, node) {
+		return false
+	}
+
+	return true
+}
+
+func matchesRequirement(req modeloutput.LabelSelectorRequirement, node Node) bool {
+	if req.Key == modeloutput.LegacyNodeNameKey {
+		return matchesNameRequirement(req, node.Name)
+	}
+
+	value, hasValue := node.Labels[req.Key]
+	switch req.Operator {
+	case modeloutput.SelectorOpIn:
+		return hasValue && containsString(req.Values, value)
+	case modeloutput.SelectorOpNotIn:
+		return !hasValue || !containsString(req.Values, value)
+	case modeloutput.SelectorOpExists:
+		return hasValue
+	case modeloutput.SelectorOpDoesNotExist:
+		return !hasValue
+	default:
+		return false
+	}
+}
+
+func matchesNameRequirement(req modeloutput.LabelSelectorRequirement, name string) bool {
+	switch req.Operator {
+	case modeloutput.SelectorOpIn:
+		return containsString(req.Values, name)
+	case modeloutput.SelectorOpNotIn:
+		return !containsString(req.Values, name)
+	case modeloutput.SelectorOpExists:
+		return true
+	case modeloutput.SelectorOpDoesNotExist:
+		return false
+	default:
+		return false
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
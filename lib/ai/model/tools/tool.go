@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 Gravitational, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import "context"
+
+// Tool is one capability the assistant's thought loop can invoke. Most
+// tools run to completion and return their result as a string; a couple,
+// like CommandExecutionTool, are handled specially by the loop instead of
+// being run (see the comment in CommandExecutionTool.Run).
+type Tool interface {
+	Name() string
+	Description() string
+	Run(ctx context.Context, toolCtx *ToolContext, input string) (string, error)
+}
+
+// ToolContext carries the request-scoped dependencies a Tool needs to run;
+// currently that's only the node inventory.
+type ToolContext struct {
+	// NodeInventory resolves the current set of nodes a command could
+	// target, and whether the requester can reach each one.
+	NodeInventory NodeInventory
+}
+
+// NodeInventory is the subset of the cluster's node list a tool needs to
+// resolve a node/label selection into concrete targets.
+type NodeInventory interface {
+	// ListNodes returns every node currently known to the cluster.
+	ListNodes(ctx context.Context) ([]Node, error)
+}
+
+// Node is the subset of a Teleport SSH node's identity tools reason about:
+// its name, its labels, and whether the caller is authorized to reach it.
+type Node struct {
+	Name       string
+	Labels     map[string]string
+	Accessible bool
+}
@@ -0,0 +1,535 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etcdbk3 implements a [backend.Backend] on top of etcd v3's
+// transactional KV API and native watch support. Unlike the legacy etcd
+// backend it does not poll for changes: the change feed is a direct
+// translation of the etcd watch channel into [backend.Event]s, and TTLs are
+// implemented with etcd leases instead of a background expiry sweep.
+package etcdbk3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/teleport/lib/backend"
+)
+
+const (
+	// Name is the value of [backend.Params] "type" that selects this backend.
+	Name = "etcdv3"
+
+	// componentName is the component name used for logging.
+	componentName = "etcdbk3"
+)
+
+const (
+	defaultDialTimeout = 30 * time.Second
+
+	// minLeaseTTL is the smallest TTL etcd will accept for a lease; items with
+	// a shorter expiry are clamped up to this value.
+	minLeaseTTL = 5 * time.Second
+)
+
+// AuthMode determines how the client authenticates to the etcd cluster.
+type AuthMode string
+
+const (
+	// StaticAuth uses the username/password and TLS client certificate (if
+	// any) configured on [Config] directly.
+	StaticAuth AuthMode = ""
+)
+
+// Check returns an error if the AuthMode is invalid.
+func (a AuthMode) Check() error {
+	switch a {
+	case StaticAuth:
+		return nil
+	default:
+		return trace.BadParameter("invalid authentication mode %q, should be %q", a, StaticAuth)
+	}
+}
+
+// Config is the configuration struct for [Backend]; outside of tests or
+// custom code, it's usually generated by converting the [backend.Params]
+// from the Teleport configuration file.
+//
+// Config intentionally mirrors the shape of [pgbk.Config]: ConnString holds
+// a comma-separated list of etcd endpoints (instead of a Postgres DSN) and
+// AuthMode selects how the client authenticates.
+type Config struct {
+	ConnString string `json:"conn_string"`
+
+	AuthMode AuthMode `json:"auth_mode"`
+
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	TLSCertFile string `json:"tls_cert_file"`
+	TLSKeyFile  string `json:"tls_key_file"`
+	TLSCAFile   string `json:"tls_ca_file"`
+	Insecure    bool   `json:"insecure"`
+
+	DialTimeout types.Duration `json:"dial_timeout"`
+}
+
+// CheckAndSetDefaults validates the config and sets default values.
+func (c *Config) CheckAndSetDefaults() error {
+	if err := c.AuthMode.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if c.ConnString == "" {
+		return trace.BadParameter("missing etcd endpoints (conn_string)")
+	}
+
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = types.Duration(defaultDialTimeout)
+	}
+
+	return nil
+}
+
+func (c *Config) endpoints() []string {
+	var out []string
+	for _, e := range strings.Split(c.ConnString, ",") {
+		e = strings.TrimSpace(e)
+		if e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.TLSCertFile == "" && c.TLSKeyFile == "" && c.TLSCAFile == "" && !c.Insecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.Insecure}
+
+	if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.TLSCAFile != "" {
+		caCert, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, trace.BadParameter("failed to parse CA certificate %q", c.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+func init() {
+	backend.RegisterBackend(Name, func(ctx context.Context, params backend.Params) (backend.Backend, error) {
+		return NewFromParams(ctx, params)
+	})
+}
+
+// NewFromParams starts and returns a [*Backend] with the given params
+// (generally read from the Teleport configuration file).
+func NewFromParams(ctx context.Context, params backend.Params) (*Backend, error) {
+	var cfg Config
+	if err := utils.ObjectToStruct(params, &cfg); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	bk, err := NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return bk, nil
+}
+
+// NewWithConfig starts and returns a [*Backend] with the given [Config].
+func NewWithConfig(ctx context.Context, cfg Config) (*Backend, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	log := logrus.WithField(trace.Component, componentName)
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.endpoints(),
+		DialTimeout: time.Duration(cfg.DialTimeout),
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		TLS:         tlsConfig,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	log.Info("Setting up backend.")
+
+	ctx, cancel := context.WithCancel(ctx)
+	b := &Backend{
+		cfg:    cfg,
+		log:    log,
+		client: client,
+		buf:    backend.NewCircularBuffer(),
+		cancel: cancel,
+		clock:  clockwork.NewRealClock(),
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.backgroundWatch(ctx)
+	}()
+
+	return b, nil
+}
+
+// Backend is an etcd v3-backed [backend.Backend]. It keeps TTLs as etcd
+// leases and drives its change feed directly from etcd's watch API, so it
+// needs no background expiry sweep or change-feed poller the way [pgbk.Backend]
+// does.
+type Backend struct {
+	cfg    Config
+	log    logrus.FieldLogger
+	client *clientv3.Client
+	buf    *backend.CircularBuffer
+	clock  clockwork.Clock
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ backend.Backend = (*Backend)(nil)
+
+func (b *Backend) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	b.buf.Close()
+	return trace.Wrap(b.client.Close())
+}
+
+// GetName implements [backend.Backend].
+func (*Backend) GetName() string {
+	return Name
+}
+
+// leaseForExpiry grants a new etcd lease whose TTL matches the
+// requested expiry, clamped to [minLeaseTTL]. A zero expiry means the item
+// never expires and no lease is attached.
+func (b *Backend) leaseForExpiry(ctx context.Context, expires time.Time) (clientv3.LeaseID, error) {
+	if expires.IsZero() {
+		return 0, nil
+	}
+
+	ttl := int64(time.Until(expires).Seconds())
+	if ttl < int64(minLeaseTTL.Seconds()) {
+		ttl = int64(minLeaseTTL.Seconds())
+	}
+
+	lease, err := b.client.Grant(ctx, ttl)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return lease.ID, nil
+}
+
+// Create implements [backend.Backend].
+func (b *Backend) Create(ctx context.Context, i backend.Item) (*backend.Lease, error) {
+	leaseID, err := b.leaseForExpiry(ctx, i.Expires)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	key := string(i.Key)
+	opts := putOpts(leaseID)
+	txn := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(i.Value), opts...))
+
+	resp, err := txn.Commit()
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+	if !resp.Succeeded {
+		return nil, trace.AlreadyExists("key %q already exists", i.Key)
+	}
+
+	return newLease(i), nil
+}
+
+// Put implements [backend.Backend].
+func (b *Backend) Put(ctx context.Context, i backend.Item) (*backend.Lease, error) {
+	leaseID, err := b.leaseForExpiry(ctx, i.Expires)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	_, err = b.client.Put(ctx, string(i.Key), string(i.Value), putOpts(leaseID)...)
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+
+	return newLease(i), nil
+}
+
+// CompareAndSwap implements [backend.Backend].
+func (b *Backend) CompareAndSwap(ctx context.Context, expected backend.Item, replaceWith backend.Item) (*backend.Lease, error) {
+	if string(expected.Key) != string(replaceWith.Key) {
+		return nil, trace.BadParameter("expected and replaceWith keys should match")
+	}
+
+	leaseID, err := b.leaseForExpiry(ctx, replaceWith.Expires)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	key := string(replaceWith.Key)
+	txn := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", string(expected.Value))).
+		Then(clientv3.OpPut(key, string(replaceWith.Value), putOpts(leaseID)...))
+
+	resp, err := txn.Commit()
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+	if !resp.Succeeded {
+		return nil, trace.CompareFailed("key %q does not exist or does not match expected", replaceWith.Key)
+	}
+
+	return newLease(replaceWith), nil
+}
+
+// Update implements [backend.Backend].
+func (b *Backend) Update(ctx context.Context, i backend.Item) (*backend.Lease, error) {
+	leaseID, err := b.leaseForExpiry(ctx, i.Expires)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	key := string(i.Key)
+	txn := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), ">", 0)).
+		Then(clientv3.OpPut(key, string(i.Value), putOpts(leaseID)...))
+
+	resp, err := txn.Commit()
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+	if !resp.Succeeded {
+		return nil, trace.NotFound("key %q does not exist", i.Key)
+	}
+
+	return newLease(i), nil
+}
+
+// Get implements [backend.Backend].
+func (b *Backend) Get(ctx context.Context, key []byte) (*backend.Item, error) {
+	resp, err := b.client.Get(ctx, string(key))
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, trace.NotFound("key %q does not exist", key)
+	}
+
+	return kvToItem(resp.Kvs[0]), nil
+}
+
+// GetRange implements [backend.Backend].
+func (b *Backend) GetRange(ctx context.Context, startKey []byte, endKey []byte, limit int) (*backend.GetResult, error) {
+	if limit <= 0 {
+		limit = backend.DefaultRangeLimit
+	}
+
+	resp, err := b.client.Get(ctx, string(startKey),
+		clientv3.WithRange(string(endKey)),
+		clientv3.WithLimit(int64(limit)),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+	)
+	if err != nil {
+		return nil, trace.Wrap(convertErr(err))
+	}
+
+	items := make([]backend.Item, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		items = append(items, *kvToItem(kv))
+	}
+
+	return &backend.GetResult{Items: items}, nil
+}
+
+// Delete implements [backend.Backend].
+func (b *Backend) Delete(ctx context.Context, key []byte) error {
+	resp, err := b.client.Delete(ctx, string(key))
+	if err != nil {
+		return trace.Wrap(convertErr(err))
+	}
+	if resp.Deleted == 0 {
+		return trace.NotFound("key %q does not exist", key)
+	}
+	return nil
+}
+
+// DeleteRange implements [backend.Backend].
+func (b *Backend) DeleteRange(ctx context.Context, startKey []byte, endKey []byte) error {
+	_, err := b.client.Delete(ctx, string(startKey), clientv3.WithRange(string(endKey)))
+	return trace.Wrap(convertErr(err))
+}
+
+// KeepAlive implements [backend.Backend].
+func (b *Backend) KeepAlive(ctx context.Context, lease backend.Lease, expires time.Time) error {
+	leaseID, err := b.leaseForExpiry(ctx, expires)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Re-attach the key to the new lease with WithIgnoreValue, so etcd
+	// reuses the stored value instead of us reading it back and putting it
+	// again; that keeps the reattach atomic with respect to a concurrent
+	// Update/CompareAndSwap on the same key, unlike a Get-then-Put.
+	key := string(lease.Key)
+	txn := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), ">", 0)).
+		Then(clientv3.OpPut(key, "", append(putOpts(leaseID), clientv3.WithIgnoreValue())...))
+
+	resp, err := txn.Commit()
+	if err != nil {
+		return trace.Wrap(convertErr(err))
+	}
+	if !resp.Succeeded {
+		return trace.NotFound("key %q does not exist", lease.Key)
+	}
+
+	return nil
+}
+
+// NewWatcher implements [backend.Backend].
+func (b *Backend) NewWatcher(ctx context.Context, watch backend.Watch) (backend.Watcher, error) {
+	return b.buf.NewWatcher(ctx, watch)
+}
+
+// CloseWatchers implements [backend.Backend].
+func (b *Backend) CloseWatchers() { b.buf.Clear() }
+
+// Clock implements [backend.Backend].
+func (b *Backend) Clock() clockwork.Clock {
+	return b.clock
+}
+
+// backgroundWatch replaces the polling backgroundChangeFeed of pgbk with a
+// direct translation of etcd's watch channel into [backend.Event]s pushed
+// into the circular buffer; it runs for the lifetime of the backend.
+func (b *Backend) backgroundWatch(ctx context.Context) {
+	watchChan := b.client.Watch(ctx, "", clientv3.WithPrefix(), clientv3.WithPrevKV())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case resp, ok := <-watchChan:
+			if !ok {
+				return
+			}
+			if err := resp.Err(); err != nil {
+				b.log.WithError(err).Warn("Watch channel closed with an error, change feed is stopping.")
+				return
+			}
+
+			for _, ev := range resp.Events {
+				b.buf.Emit(convertEvent(ev))
+			}
+		}
+	}
+}
+
+func putOpts(leaseID clientv3.LeaseID) []clientv3.OpOption {
+	if leaseID == 0 {
+		return nil
+	}
+	return []clientv3.OpOption{clientv3.WithLease(leaseID)}
+}
+
+func kvToItem(kv *mvccpb.KeyValue) *backend.Item {
+	return &backend.Item{
+		Key:   kv.Key,
+		Value: kv.Value,
+	}
+}
+
+func convertEvent(ev *clientv3.Event) backend.Event {
+	if ev.Type == mvccpb.DELETE {
+		key := ev.Kv.Key
+		if ev.PrevKv != nil {
+			key = ev.PrevKv.Key
+		}
+		return backend.Event{
+			Type: types.OpDelete,
+			Item: backend.Item{Key: key},
+		}
+	}
+
+	return backend.Event{
+		Type: types.OpPut,
+		Item: backend.Item{
+			Key:   ev.Kv.Key,
+			Value: ev.Kv.Value,
+		},
+	}
+}
+
+func convertErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if status.Code(err) == codes.Unavailable {
+		return trace.ConnectionProblem(err, "etcd cluster unavailable")
+	}
+	return err
+}
+
+func newLease(i backend.Item) *backend.Lease {
+	if i.Expires.IsZero() {
+		return &backend.Lease{}
+	}
+	return &backend.Lease{Key: i.Key}
+}
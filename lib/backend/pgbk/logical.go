@@ -0,0 +1,278 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgbk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/backend"
+)
+
+const (
+	// standbyStatusInterval is how often we reply to the server with the LSN
+	// we've applied up to, regardless of whether the server asked for it.
+	standbyStatusInterval = 10 * time.Second
+
+	// temporarySlotPrefix namespaces temporary slots created by this process
+	// when Config.ReplicationSlot is left empty, so that a crash-restart
+	// doesn't collide with an orphaned slot from a previous run.
+	temporarySlotPrefix = "teleport_kv_"
+)
+
+// backgroundLogicalChangeFeed streams row changes from the kv_pub
+// publication over a dedicated replication connection, decodes pgoutput
+// Insert/Update/Delete messages, and emits the corresponding [backend.Event]s
+// into the circular buffer. It replaces polling with sub-second latency
+// delivery of changes made by any auth server in the cluster.
+//
+// It returns an error (without retrying) if the replication connection,
+// slot creation, or the initial START_REPLICATION can't be established, so
+// the caller can fall back to polling. Once streaming has started, an error
+// receiving or parsing a replication message also ends the feed and is
+// returned; it is not retried here. It returns nil when ctx is canceled.
+func (b *Backend) backgroundLogicalChangeFeed(ctx context.Context) error {
+	connConfig := b.pool.Config().ConnConfig.Copy()
+	connConfig.RuntimeParams["replication"] = "database"
+
+	conn, err := pgconn.ConnectConfig(ctx, &connConfig.Config)
+	if err != nil {
+		return trace.Wrap(err, "opening replication connection")
+	}
+	defer conn.Close(ctx)
+
+	slotName := b.cfg.ReplicationSlot
+	temporary := slotName == ""
+	if temporary {
+		slotName = temporarySlotPrefix + newRevision()
+	}
+
+	sysident, err := pglogrepl.IdentifySystem(ctx, conn)
+	if err != nil {
+		return trace.Wrap(err, "identifying system")
+	}
+
+	// startLSN is where we tell the server to begin streaming from; it
+	// defaults to the server's current position for a brand-new slot, but a
+	// reused slot must resume from its own restart LSN instead, or every
+	// change since the slot was last attended would be silently skipped.
+	startLSN := sysident.XLogPos
+	if _, err := pglogrepl.CreateReplicationSlot(ctx, conn, slotName, "pgoutput",
+		pglogrepl.CreateReplicationSlotOptions{Temporary: temporary}); err != nil {
+		var pgErr *pgconn.PgError
+		// 42710 is duplicate_object: a permanent slot from a previous run is
+		// still around and can be reused as-is.
+		if !(errors.As(err, &pgErr) && pgErr.Code == "42710") {
+			return trace.Wrap(err, "creating replication slot %q", slotName)
+		}
+
+		startLSN, err = slotRestartLSN(ctx, conn, slotName)
+		if err != nil {
+			return trace.Wrap(err, "looking up restart LSN for existing replication slot %q", slotName)
+		}
+	}
+
+	pluginArgs := []string{
+		"proto_version '1'",
+		"publication_names '" + b.cfg.PublicationName + "'",
+	}
+	if err := pglogrepl.StartReplication(ctx, conn, slotName, startLSN,
+		pglogrepl.StartReplicationOptions{PluginArgs: pluginArgs}); err != nil {
+		return trace.Wrap(err, "starting replication")
+	}
+
+	b.log.WithField("slot", slotName).Info("Logical replication change feed started.")
+
+	lastApplied := startLSN
+	relations := make(map[uint32]*pglogrepl.RelationMessageV2)
+	ticker := time.NewTicker(standbyStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := sendStandbyStatus(ctx, conn, lastApplied); err != nil {
+				b.log.WithError(err).Warn("Failed to send standby status update.")
+			}
+		default:
+		}
+
+		msgCtx, cancel := context.WithTimeout(ctx, standbyStatusInterval)
+		rawMsg, err := conn.ReceiveMessage(msgCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) {
+				continue
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return trace.Wrap(err, "receiving replication message")
+		}
+
+		msg, ok := rawMsg.(*pgproto3.CopyData)
+		if !ok {
+			continue
+		}
+
+		switch msg.Data[0] {
+		case pglogrepl.PrimaryKeepaliveMessageByteID:
+			ka, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+			if err != nil {
+				return trace.Wrap(err, "parsing keepalive")
+			}
+			if ka.ReplyRequested {
+				if err := sendStandbyStatus(ctx, conn, lastApplied); err != nil {
+					b.log.WithError(err).Warn("Failed to reply to keepalive.")
+				}
+			}
+
+		case pglogrepl.XLogDataByteID:
+			xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+			if err != nil {
+				return trace.Wrap(err, "parsing xlog data")
+			}
+
+			event, err := decodeWALMessage(xld.WALData, relations)
+			if err != nil {
+				b.log.WithError(err).Warn("Failed to decode logical replication message, skipping.")
+				break
+			}
+			if event != nil {
+				b.buf.Emit(*event)
+			}
+
+			if xld.WALStart+pglogrepl.LSN(len(xld.WALData)) > lastApplied {
+				lastApplied = xld.WALStart + pglogrepl.LSN(len(xld.WALData))
+			}
+		}
+	}
+}
+
+// slotRestartLSN looks up the restart_lsn of an existing replication slot,
+// i.e. the oldest WAL position the server still guarantees to have for it.
+// Resuming from here (rather than the server's current position) is what
+// makes reusing a persistent slot across restarts lossless.
+func slotRestartLSN(ctx context.Context, conn *pgconn.PgConn, slotName string) (pglogrepl.LSN, error) {
+	result := conn.ExecParams(
+		ctx,
+		"SELECT restart_lsn FROM pg_replication_slots WHERE slot_name = $1",
+		[][]byte{[]byte(slotName)},
+		nil, nil, nil,
+	)
+	res, err := result.Read()
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	if len(res.Rows) != 1 {
+		return 0, trace.NotFound("replication slot %q not found", slotName)
+	}
+
+	lsn, err := pglogrepl.ParseLSN(string(res.Rows[0][0]))
+	if err != nil {
+		return 0, trace.Wrap(err, "parsing restart_lsn for slot %q", slotName)
+	}
+	return lsn, nil
+}
+
+func sendStandbyStatus(ctx context.Context, conn *pgconn.PgConn, applied pglogrepl.LSN) error {
+	return pglogrepl.SendStandbyStatusUpdate(ctx, conn, pglogrepl.StandbyStatusUpdate{
+		WALWritePosition: applied,
+		WALFlushPosition: applied,
+		WALApplyPosition: applied,
+	})
+}
+
+// decodeWALMessage turns a single pgoutput message into a [backend.Event].
+// Relation (table schema) messages are cached and return no event; Begin/
+// Commit/Truncate/Origin messages are ignored.
+func decodeWALMessage(data []byte, relations map[uint32]*pglogrepl.RelationMessageV2) (*backend.Event, error) {
+	msg, err := pglogrepl.ParseV2(data, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch m := msg.(type) {
+	case *pglogrepl.RelationMessageV2:
+		relations[m.RelationID] = m
+		return nil, nil
+
+	case *pglogrepl.InsertMessageV2:
+		item, err := tupleToItem(relations[m.RelationID], m.Tuple)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &backend.Event{Type: types.OpPut, Item: *item}, nil
+
+	case *pglogrepl.UpdateMessageV2:
+		item, err := tupleToItem(relations[m.RelationID], m.NewTuple)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &backend.Event{Type: types.OpPut, Item: *item}, nil
+
+	case *pglogrepl.DeleteMessageV2:
+		tuple := m.OldTuple
+		rel := relations[m.RelationID]
+		item, err := tupleToItem(rel, tuple)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &backend.Event{Type: types.OpDelete, Item: backend.Item{Key: item.Key}}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// tupleToItem decodes a pgoutput tuple of the kv table, matching each column
+// to the key, value and expires fields by the name the relation message
+// gives it; other columns are ignored.
+func tupleToItem(rel *pglogrepl.RelationMessageV2, tuple *pglogrepl.TupleData) (*backend.Item, error) {
+	if rel == nil || tuple == nil {
+		return nil, trace.BadParameter("missing relation or tuple data for kv change")
+	}
+
+	item := &backend.Item{}
+	for i, col := range tuple.Columns {
+		if i >= len(rel.Columns) {
+			break
+		}
+		switch rel.Columns[i].Name {
+		case "key":
+			item.Key = col.Data
+		case "value":
+			item.Value = col.Data
+		case "expires":
+			if len(col.Data) > 0 {
+				if t, err := time.Parse(time.RFC3339Nano, string(col.Data)); err == nil {
+					item.Expires = t
+				}
+			}
+		}
+	}
+
+	return item, nil
+}
@@ -49,8 +49,40 @@ const (
 
 	defaultExpiryBatchSize = 1000
 	defaultExpiryInterval  = 30 * time.Second
+
+	// defaultPublicationName is the publication created by the kv schema
+	// migration and used for the logical change feed.
+	defaultPublicationName = "kv_pub"
 )
 
+// ChangeFeedMode selects how [Backend] learns about changes made to the kv
+// table by other auth servers.
+type ChangeFeedMode string
+
+const (
+	// ChangeFeedModePoll repeatedly scans the kv table for rows modified
+	// since the last poll; it's the original implementation and remains the
+	// default, since it works with any Postgres user regardless of
+	// privileges.
+	ChangeFeedModePoll ChangeFeedMode = "poll"
+	// ChangeFeedModeLogical streams changes from a logical replication slot
+	// using the kv_pub publication, decoding pgoutput messages directly into
+	// [backend.Event]s instead of polling. It requires the configured user to
+	// have the REPLICATION privilege and falls back to ChangeFeedModePoll if
+	// the slot cannot be created.
+	ChangeFeedModeLogical ChangeFeedMode = "logical"
+)
+
+// Check returns an error if the ChangeFeedMode is invalid.
+func (m ChangeFeedMode) Check() error {
+	switch m {
+	case ChangeFeedModePoll, ChangeFeedModeLogical:
+		return nil
+	default:
+		return trace.BadParameter("invalid change feed mode %q, should be %q or %q", m, ChangeFeedModePoll, ChangeFeedModeLogical)
+	}
+}
+
 // AuthMode determines if we should use some environment-specific authentication
 // mechanism or credentials.
 type AuthMode string
@@ -85,6 +117,18 @@ type Config struct {
 	ChangeFeedPollInterval types.Duration `json:"change_feed_poll_interval"`
 	ChangeFeedBatchSize    int            `json:"change_feed_batch_size"`
 
+	// ChangeFeedMode selects between polling and logical replication. It
+	// defaults to ChangeFeedModePoll.
+	ChangeFeedMode ChangeFeedMode `json:"change_feed_mode"`
+	// ReplicationSlot is the name of the logical replication slot to create
+	// (or reuse) when ChangeFeedMode is ChangeFeedModeLogical. If empty, a
+	// temporary slot is created for the lifetime of the process.
+	ReplicationSlot string `json:"replication_slot"`
+	// PublicationName is the publication to stream from when ChangeFeedMode
+	// is ChangeFeedModeLogical. Defaults to kv_pub, the publication created
+	// by the backend's schema migration.
+	PublicationName string `json:"publication_name"`
+
 	DisableExpiry   bool           `json:"disable_expiry"`
 	ExpiryInterval  types.Duration `json:"expiry_interval"`
 	ExpiryBatchSize int            `json:"expiry_batch_size"`
@@ -121,6 +165,16 @@ func (c *Config) CheckAndSetDefaults() error {
 		c.ExpiryBatchSize = defaultExpiryBatchSize
 	}
 
+	if c.ChangeFeedMode == "" {
+		c.ChangeFeedMode = ChangeFeedModePoll
+	}
+	if err := c.ChangeFeedMode.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if c.PublicationName == "" {
+		c.PublicationName = defaultPublicationName
+	}
+
 	return nil
 }
 
@@ -203,12 +257,32 @@ func NewWithConfig(ctx context.Context, cfg Config) (*Backend, error) {
 	b.wg.Add(1)
 	go func() {
 		defer b.wg.Done()
-		b.backgroundChangeFeed(ctx)
+		b.runChangeFeed(ctx)
 	}()
 
 	return b, nil
 }
 
+// runChangeFeed picks between the logical replication change feed and the
+// polling fallback. It always falls back to polling if logical replication
+// isn't requested, or if the logical feed can't get off the ground (for
+// example because the configured user lacks the REPLICATION privilege or the
+// slot couldn't be created).
+func (b *Backend) runChangeFeed(ctx context.Context) {
+	if b.cfg.ChangeFeedMode != ChangeFeedModeLogical {
+		b.backgroundChangeFeed(ctx)
+		return
+	}
+
+	if err := b.backgroundLogicalChangeFeed(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		b.log.WithError(err).Warn("Logical replication change feed failed to start, falling back to polling.")
+		b.backgroundChangeFeed(ctx)
+	}
+}
+
 // Backend is a PostgreSQL-backed [backend.Backend].
 type Backend struct {
 	cfg  Config
@@ -0,0 +1,250 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package awsv2 mirrors the NewDatabaseFrom* constructors in lib/services,
+// but accepts aws-sdk-go-v2 value types instead of aws-sdk-go v1 pointer
+// types. It exists so the discovery subsystem can adopt the modular v2
+// clients (context-aware pagination, middleware, smaller binaries) one
+// service at a time without shimming every v2 response field back through
+// aws.String(...)/aws.Int64(...) to satisfy the v1 constructors.
+package awsv2
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	memorydbtypes "github.com/aws/aws-sdk-go-v2/service/memorydb/types"
+	redshifttypes "github.com/aws/aws-sdk-go-v2/service/redshift/types"
+	rsstypes "github.com/aws/aws-sdk-go-v2/service/redshiftserverless/types"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services/common"
+)
+
+// Database is the v2 equivalent of lib/services' per-engine Database result
+// types: a single converted database, ready for the discovery catalog.
+type Database struct {
+	// Name is the discovered resource's Teleport name.
+	Name string
+	// URI is the database's connection endpoint, host:port.
+	URI string
+	// Labels are the discovery labels attached by the converter.
+	Labels map[string]string
+}
+
+// NewDatabaseFromRedshiftCluster converts a v2 Redshift cluster into a
+// Database.
+func NewDatabaseFromRedshiftCluster(cluster redshifttypes.Cluster, extraLabels map[string]string) (*Database, error) {
+	if cluster.ClusterIdentifier == nil || cluster.Endpoint == nil || cluster.Endpoint.Address == nil {
+		return nil, trace.BadParameter("cluster is missing an identifier or endpoint")
+	}
+
+	return &Database{
+		Name:   *cluster.ClusterIdentifier,
+		URI:    formatHostPort(*cluster.Endpoint.Address, cluster.Endpoint.Port),
+		Labels: buildLabels("", extraLabels, tagsToLabels(cluster.Tags)),
+	}, nil
+}
+
+// NewDatabaseFromMemoryDBCluster converts a v2 MemoryDB cluster's cluster
+// endpoint into a Database.
+func NewDatabaseFromMemoryDBCluster(cluster memorydbtypes.Cluster, extraLabels map[string]string) (*Database, error) {
+	if cluster.Name == nil || cluster.ClusterEndpoint == nil || cluster.ClusterEndpoint.Address == nil {
+		return nil, trace.BadParameter("cluster is missing a name or endpoint")
+	}
+
+	return &Database{
+		Name:   *cluster.Name,
+		URI:    formatHostPort(*cluster.ClusterEndpoint.Address, cluster.ClusterEndpoint.Port),
+		Labels: buildLabels("", extraLabels, nil),
+	}, nil
+}
+
+// NewDatabasesFromMemoryDBShards converts a MemoryDB cluster's shards into
+// per-shard Databases, mirroring NewDatabasesFromElastiCacheShards: each
+// shard yields a "<cluster>-shard-<ShardName>" database for its primary
+// node and a "<cluster>-shard-<ShardName>-reader" database for its first
+// replica node, if any.
+func NewDatabasesFromMemoryDBShards(clusterName string, shards []memorydbtypes.Shard, extraLabels map[string]string) []Database {
+	var databases []Database
+	for _, shard := range shards {
+		if shard.Name == nil {
+			continue
+		}
+		shardIDLabels := map[string]string{
+			"discovery/shard-id":      *shard.Name,
+			"discovery/node-group-id": *shard.Name,
+		}
+
+		// MemoryDB doesn't label individual nodes as primary/replica in the
+		// v2 API response; by AWS convention the first node in Nodes is
+		// always the shard's primary, and any node after it is a replica.
+		for i, node := range shard.Nodes {
+			if node.Endpoint == nil || node.Endpoint.Address == nil {
+				continue
+			}
+			name := clusterName + "-shard-" + *shard.Name
+			endpointType := "primary"
+			if i > 0 {
+				name += "-reader"
+				endpointType = "reader"
+			}
+			databases = append(databases, Database{
+				Name:   name,
+				URI:    formatHostPort(*node.Endpoint.Address, node.Endpoint.Port),
+				Labels: buildLabels(endpointType, extraLabels, shardIDLabels),
+			})
+			if i > 0 {
+				// Only the first replica becomes the shard's reader
+				// database; additional replicas are redundant for
+				// read-routing purposes.
+				break
+			}
+		}
+	}
+	return databases
+}
+
+// NewDatabaseFromElastiCacheConfigurationEndpoint converts a v2 ElastiCache
+// replication group's cluster-mode-enabled configuration endpoint into a
+// Database.
+func NewDatabaseFromElastiCacheConfigurationEndpoint(group types.ReplicationGroup, extraLabels map[string]string) (*Database, error) {
+	if group.ReplicationGroupId == nil || group.ConfigurationEndpoint == nil || group.ConfigurationEndpoint.Address == nil {
+		return nil, trace.BadParameter("replication group is missing an ID or configuration endpoint")
+	}
+
+	return &Database{
+		Name:   *group.ReplicationGroupId,
+		URI:    formatHostPort(*group.ConfigurationEndpoint.Address, group.ConfigurationEndpoint.Port),
+		Labels: buildLabels("", extraLabels, nil),
+	}, nil
+}
+
+// NewDatabasesFromElastiCacheNodeGroups converts every node group in a v2
+// ElastiCache replication group into one or two Databases each: a
+// "<cluster>-shard-<NodeGroupId>" database for the shard's primary
+// endpoint, and a "<cluster>-shard-<NodeGroupId>-reader" database for its
+// reader endpoint when the shard has read replicas. This is the
+// cluster-mode-enabled path, where the replication group's own
+// ConfigurationEndpoint hides per-shard routing; callers that want to pin
+// READONLY traffic to a specific shard need these per-shard reader
+// databases rather than the single configuration endpoint.
+func NewDatabasesFromElastiCacheNodeGroups(group types.ReplicationGroup, extraLabels map[string]string) ([]Database, error) {
+	if group.ReplicationGroupId == nil {
+		return nil, trace.BadParameter("replication group is missing an ID")
+	}
+	return NewDatabasesFromElastiCacheShards(*group.ReplicationGroupId, group.NodeGroups, extraLabels), nil
+}
+
+// NewDatabasesFromElastiCacheShards converts a raw list of v2 NodeGroups
+// (a replication group's shards) into per-shard Databases. It's split out
+// from NewDatabasesFromElastiCacheNodeGroups so callers with only the shard
+// list on hand (e.g. a DescribeCacheClusters-based path) don't need to
+// build a full ReplicationGroup value just to call it.
+func NewDatabasesFromElastiCacheShards(clusterName string, shards []types.NodeGroup, extraLabels map[string]string) []Database {
+	var databases []Database
+	for _, shard := range shards {
+		if shard.NodeGroupId == nil {
+			continue
+		}
+		shardIDLabels := map[string]string{
+			"discovery/shard-id":      *shard.NodeGroupId,
+			"discovery/node-group-id": *shard.NodeGroupId,
+		}
+
+		if shard.PrimaryEndpoint != nil && shard.PrimaryEndpoint.Address != nil {
+			databases = append(databases, Database{
+				Name:   clusterName + "-shard-" + *shard.NodeGroupId,
+				URI:    formatHostPort(*shard.PrimaryEndpoint.Address, shard.PrimaryEndpoint.Port),
+				Labels: buildLabels("primary", extraLabels, shardIDLabels),
+			})
+		}
+		if shard.ReaderEndpoint != nil && shard.ReaderEndpoint.Address != nil {
+			databases = append(databases, Database{
+				Name:   clusterName + "-shard-" + *shard.NodeGroupId + "-reader",
+				URI:    formatHostPort(*shard.ReaderEndpoint.Address, shard.ReaderEndpoint.Port),
+				Labels: buildLabels("reader", extraLabels, shardIDLabels),
+			})
+		}
+	}
+	return databases
+}
+
+// NewDatabaseFromRedshiftServerlessWorkgroup converts a v2 Redshift
+// Serverless workgroup's default endpoint into a Database.
+func NewDatabaseFromRedshiftServerlessWorkgroup(wg rsstypes.Workgroup, tags map[string]string) (*Database, error) {
+	if wg.WorkgroupName == nil || wg.Endpoint == nil || wg.Endpoint.Address == nil {
+		return nil, trace.BadParameter("workgroup is missing a name or endpoint")
+	}
+
+	extraLabels := map[string]string{"workgroup-name": *wg.WorkgroupName}
+	if wg.NamespaceName != nil {
+		extraLabels["namespace-name"] = *wg.NamespaceName
+	}
+
+	return &Database{
+		Name:   *wg.WorkgroupName,
+		URI:    formatHostPort(*wg.Endpoint.Address, wg.Endpoint.Port),
+		Labels: buildLabels("", tags, extraLabels),
+	}, nil
+}
+
+// NewDatabaseFromRedshiftServerlessVPCEndpoint converts one of a v2
+// workgroup's VPC endpoints into a Database.
+func NewDatabaseFromRedshiftServerlessVPCEndpoint(endpoint rsstypes.EndpointAccess, wg rsstypes.Workgroup, tags map[string]string) (*Database, error) {
+	if endpoint.EndpointName == nil || endpoint.Address == nil {
+		return nil, trace.BadParameter("VPC endpoint is missing a name or address")
+	}
+	if wg.WorkgroupName == nil {
+		return nil, trace.BadParameter("VPC endpoint requires its parent workgroup")
+	}
+
+	extraLabels := map[string]string{"workgroup-name": *wg.WorkgroupName}
+	if wg.NamespaceName != nil {
+		extraLabels["namespace-name"] = *wg.NamespaceName
+	}
+
+	return &Database{
+		Name:   *wg.WorkgroupName + "-" + *endpoint.EndpointName,
+		URI:    formatHostPort(*endpoint.Address, endpoint.Port),
+		Labels: buildLabels("", tags, extraLabels),
+	}, nil
+}
+
+func formatHostPort(host string, port int32) string {
+	if port == 0 {
+		return host
+	}
+	return host + ":" + itoa32(port)
+}
+
+func mergeLabels(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// buildLabels assembles a converted database's discovery labels through
+// common.AWSMetadataProvider instead of each constructor above hand-rolling
+// its own CloudLabel/endpoint-type bookkeeping, the same machinery the
+// Azure and GCP converters in services/common already go through. b wins
+// over a on key collisions, matching mergeLabels.
+func buildLabels(endpoint string, a, b map[string]string) map[string]string {
+	return common.BuildLabels(common.AWSMetadataProvider{}, common.AWSResource{}, endpoint, mergeLabels(a, b))
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsv2
+
+import (
+	"testing"
+
+	redshifttypes "github.com/aws/aws-sdk-go-v2/service/redshift/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services/common"
+)
+
+func TestNewDatabaseFromRedshiftCluster(t *testing.T) {
+	cluster := RedshiftCluster("my-cluster", map[string]string{"env": "prod"})
+
+	db, err := NewDatabaseFromRedshiftCluster(cluster, map[string]string{"team": "data"})
+	require.NoError(t, err)
+	require.Equal(t, "my-cluster", db.Name)
+	require.Equal(t, "my-cluster.abcdefghijkl.us-east-1.redshift.amazonaws.com:5439", db.URI)
+	require.Equal(t, "prod", db.Labels["env"])
+	require.Equal(t, "data", db.Labels["team"])
+	require.Equal(t, common.CloudAWS, db.Labels[common.CloudLabel])
+}
+
+func TestNewDatabaseFromRedshiftServerlessWorkgroup(t *testing.T) {
+	wg := RedshiftServerlessWorkgroup("my-workgroup", "my-namespace")
+
+	db, err := NewDatabaseFromRedshiftServerlessWorkgroup(wg, map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, "my-workgroup", db.Name)
+	require.Equal(t, "my-workgroup.123456789012.eu-west-2.redshift-serverless.amazonaws.com:5439", db.URI)
+	require.Equal(t, "my-namespace", db.Labels["namespace-name"])
+	require.Equal(t, "my-workgroup", db.Labels["workgroup-name"])
+	require.Equal(t, "prod", db.Labels["env"])
+}
+
+func TestNewDatabaseFromRedshiftClusterMissingEndpoint(t *testing.T) {
+	name := "my-cluster"
+	_, err := NewDatabaseFromRedshiftCluster(redshifttypes.Cluster{ClusterIdentifier: &name}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsv2
+
+import (
+	redshifttypes "github.com/aws/aws-sdk-go-v2/service/redshift/types"
+	rsstypes "github.com/aws/aws-sdk-go-v2/service/redshiftserverless/types"
+)
+
+// RedshiftServerlessWorkgroup builds a v2 Workgroup value for tests,
+// analogous to mocks.RedshiftServerlessWorkgroup in the v1 package.
+func RedshiftServerlessWorkgroup(name, namespace string) rsstypes.Workgroup {
+	return rsstypes.Workgroup{
+		WorkgroupName: &name,
+		NamespaceName: &namespace,
+		Endpoint: &rsstypes.Endpoint{
+			Address: stringPtr(name + ".123456789012.eu-west-2.redshift-serverless.amazonaws.com"),
+			Port:    5439,
+		},
+	}
+}
+
+// RedshiftCluster builds a v2 Cluster value for tests.
+func RedshiftCluster(name string, tags map[string]string) redshifttypes.Cluster {
+	tagList := make([]redshifttypes.Tag, 0, len(tags))
+	for k, v := range tags {
+		k, v := k, v
+		tagList = append(tagList, redshifttypes.Tag{Key: &k, Value: &v})
+	}
+	return redshifttypes.Cluster{
+		ClusterIdentifier: &name,
+		Endpoint: &redshifttypes.Endpoint{
+			Address: stringPtr(name + ".abcdefghijkl.us-east-1.redshift.amazonaws.com"),
+			Port:    5439,
+		},
+		Tags: tagList,
+	}
+}
+
+func stringPtr(s string) *string { return &s }
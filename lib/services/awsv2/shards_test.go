@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsv2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	memorydbtypes "github.com/aws/aws-sdk-go-v2/service/memorydb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func strp(s string) *string { return &s }
+
+func TestNewDatabasesFromElastiCacheShards(t *testing.T) {
+	shards := []types.NodeGroup{
+		{
+			NodeGroupId:     strp("0001"),
+			PrimaryEndpoint: &types.Endpoint{Address: strp("shard1-primary.cache.amazonaws.com"), Port: 6379},
+			ReaderEndpoint:  &types.Endpoint{Address: strp("shard1-reader.cache.amazonaws.com"), Port: 6379},
+		},
+		{
+			NodeGroupId:     strp("0002"),
+			PrimaryEndpoint: &types.Endpoint{Address: strp("shard2-primary.cache.amazonaws.com"), Port: 6379},
+		},
+	}
+
+	databases := NewDatabasesFromElastiCacheShards("my-cluster", shards, map[string]string{"env": "prod"})
+	require.Len(t, databases, 3)
+
+	byName := make(map[string]Database, len(databases))
+	for _, db := range databases {
+		byName[db.Name] = db
+	}
+
+	primary, ok := byName["my-cluster-shard-0001"]
+	require.True(t, ok)
+	require.Equal(t, "shard1-primary.cache.amazonaws.com:6379", primary.URI)
+	require.Equal(t, "0001", primary.Labels["discovery/shard-id"])
+	require.Equal(t, "prod", primary.Labels["env"])
+	require.Equal(t, "primary", primary.Labels["endpoint-type"])
+
+	reader, ok := byName["my-cluster-shard-0001-reader"]
+	require.True(t, ok)
+	require.Equal(t, "shard1-reader.cache.amazonaws.com:6379", reader.URI)
+	require.Equal(t, "reader", reader.Labels["endpoint-type"])
+
+	_, hasReader := byName["my-cluster-shard-0002-reader"]
+	require.False(t, hasReader)
+}
+
+func TestNewDatabasesFromElastiCacheNodeGroupsUsesShards(t *testing.T) {
+	id := "my-cluster"
+	group := types.ReplicationGroup{
+		ReplicationGroupId: &id,
+		NodeGroups: []types.NodeGroup{
+			{NodeGroupId: strp("0001"), PrimaryEndpoint: &types.Endpoint{Address: strp("primary.cache.amazonaws.com"), Port: 6379}},
+		},
+	}
+
+	databases, err := NewDatabasesFromElastiCacheNodeGroups(group, nil)
+	require.NoError(t, err)
+	require.Len(t, databases, 1)
+	require.Equal(t, "my-cluster-shard-0001", databases[0].Name)
+}
+
+func TestNewDatabasesFromMemoryDBShards(t *testing.T) {
+	shards := []memorydbtypes.Shard{
+		{
+			Name: strp("0001"),
+			Nodes: []memorydbtypes.Node{
+				{Name: strp("node-1"), Endpoint: &memorydbtypes.Endpoint{Address: strp("node1.memorydb.amazonaws.com"), Port: 6379}},
+				{Name: strp("node-2"), Endpoint: &memorydbtypes.Endpoint{Address: strp("node2.memorydb.amazonaws.com"), Port: 6379}},
+			},
+		},
+	}
+
+	databases := NewDatabasesFromMemoryDBShards("my-cluster", shards, nil)
+	require.Len(t, databases, 2)
+	require.Equal(t, "my-cluster-shard-0001", databases[0].Name)
+	require.Equal(t, "node1.memorydb.amazonaws.com:6379", databases[0].URI)
+	require.Equal(t, "my-cluster-shard-0001-reader", databases[1].Name)
+	require.Equal(t, "node2.memorydb.amazonaws.com:6379", databases[1].URI)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsv2
+
+import (
+	"strconv"
+
+	redshifttypes "github.com/aws/aws-sdk-go-v2/service/redshift/types"
+)
+
+// tagsToLabels converts a v2 Redshift tag list into a label map, skipping
+// any tag missing a key or value. Each engine's v2 tag struct uses the same
+// Key/Value-pointer shape, so this same pattern covers the others too; it's
+// kept per-type rather than generic since Go generics can't reach into an
+// arbitrary T's fields without a shared interface, and these SDK structs
+// don't implement one.
+func tagsToLabels(tags []redshifttypes.Tag) map[string]string {
+	labels := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag.Key == nil || tag.Value == nil {
+			continue
+		}
+		labels[*tag.Key] = *tag.Value
+	}
+	return labels
+}
+
+// itoa32 formats a v2 int32 port for use in a host:port URI.
+func itoa32(n int32) string {
+	return strconv.FormatInt(int64(n), 10)
+}
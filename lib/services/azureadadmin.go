@@ -0,0 +1,47 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import "github.com/gravitational/trace"
+
+// azureADAdminTemplateVar is the role template variable
+// MakeAzureDatabaseLoginUsername resolves against a server's discovered
+// Azure AD administrator, e.g. db_users: ['{{internal.azure_ad_admin}}'].
+const azureADAdminTemplateVar = "{{internal.azure_ad_admin}}"
+
+// WithAzureFlexServerAdmin sets db's Admin block from the server's Azure
+// AD administrator, as returned by the Flex Server's
+// AzureADAdministratorsClient.NewListByServerPager. A server with no AAD
+// administrator configured is left with a zero-value Admin.
+func WithAzureFlexServerAdmin(db AzureFlexDatabase, login, objectID string) AzureFlexDatabase {
+	db.Admin = AzureDatabaseAdmin{Login: login, ObjectID: objectID}
+	return db
+}
+
+// MakeAzureDatabaseLoginUsername resolves a requested db_users entry
+// against db, substituting the discovered Azure AD administrator login
+// when requested is the azureADAdminTemplateVar template, so operators no
+// longer have to hardcode the AAD admin username in role templates.
+func MakeAzureDatabaseLoginUsername(db AzureFlexDatabase, requested string) (string, error) {
+	if requested != azureADAdminTemplateVar {
+		return requested, nil
+	}
+	if db.Admin.Login == "" {
+		return "", trace.NotFound("database %q has no discovered Azure AD administrator", db.Name)
+	}
+	return db.Admin.Login, nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAzureFlexServerAdmin(t *testing.T) {
+	db := AzureFlexDatabase{Name: "my-mysql"}
+	db = WithAzureFlexServerAdmin(db, "admin@example.com", "00000000-0000-0000-0000-000000000000")
+	require.Equal(t, "admin@example.com", db.Admin.Login)
+	require.Equal(t, "00000000-0000-0000-0000-000000000000", db.Admin.ObjectID)
+}
+
+func TestMakeAzureDatabaseLoginUsername(t *testing.T) {
+	db := WithAzureFlexServerAdmin(AzureFlexDatabase{Name: "my-mysql"}, "admin@example.com", "obj-1")
+
+	username, err := MakeAzureDatabaseLoginUsername(db, "{{internal.azure_ad_admin}}")
+	require.NoError(t, err)
+	require.Equal(t, "admin@example.com", username)
+
+	username, err = MakeAzureDatabaseLoginUsername(db, "explicit-user")
+	require.NoError(t, err)
+	require.Equal(t, "explicit-user", username)
+
+	_, err = MakeAzureDatabaseLoginUsername(AzureFlexDatabase{Name: "no-admin"}, "{{internal.azure_ad_admin}}")
+	require.Error(t, err)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/gravitational/trace"
+)
+
+// Azure discovery label keys attached by the Azure Flexible Server
+// converters below.
+const (
+	// DiscoveryLabelAzureResourceGroup names the resource group a
+	// discovered Azure resource lives in.
+	DiscoveryLabelAzureResourceGroup = "discovery/azure-resource-group"
+	// DiscoveryLabelAzureSubscriptionID names the subscription a
+	// discovered Azure resource belongs to.
+	DiscoveryLabelAzureSubscriptionID = "discovery/azure-subscription-id"
+	// DiscoveryLabelAzureReplicationRole is "Source" or "Replica",
+	// mirroring the Flex Server API's replicationRole property.
+	DiscoveryLabelAzureReplicationRole = "discovery/azure-replication-role"
+	// DiscoveryLabelAzureSourceServer is the resource ID of a replica's
+	// source server, set only when DiscoveryLabelAzureReplicationRole is
+	// "Replica".
+	DiscoveryLabelAzureSourceServer = "discovery/azure-source-server"
+	// DiscoveryLabelAzureDatabase names the server-side database a
+	// per-database resource was enrolled from.
+	DiscoveryLabelAzureDatabase = "discovery/azure-database"
+)
+
+// AzureMySQLFlexServer is the (planned) armmysqlflexibleservers.Server
+// subset NewDatabaseFromAzureMySQLFlexServer needs.
+type AzureMySQLFlexServer struct {
+	Name                   string
+	FQDN                   string
+	ResourceID             string
+	ResourceGroup          string
+	SubscriptionID         string
+	ReplicationRole        string
+	SourceServerResourceID string
+	// Location is the Azure region the server was created in, e.g. "eastus".
+	Location string
+}
+
+// AzurePostgresFlexServer is the (planned)
+// armpostgresqlflexibleservers.Server subset
+// NewDatabaseFromAzurePostgresFlexServer needs, structurally identical to
+// AzureMySQLFlexServer.
+type AzurePostgresFlexServer struct {
+	Name                   string
+	FQDN                   string
+	ResourceID             string
+	ResourceGroup          string
+	SubscriptionID         string
+	ReplicationRole        string
+	SourceServerResourceID string
+}
+
+// AzureFlexDatabase is a database discovered from an Azure MySQL or
+// Postgres Flexible Server, or one of its child databases.
+type AzureFlexDatabase struct {
+	// Name is the discovered resource's Teleport name.
+	Name string
+	// ResourceID is the server's Azure resource ID, used to match a
+	// replica's SourceServerResourceID back to its source.
+	ResourceID string
+	// Protocol is "mysql" or "postgres".
+	Protocol string
+	// URI is the server's FQDN:port.
+	URI string
+	// Labels carries the discovery/azure-* labels plus any tags passed in.
+	Labels map[string]string
+	// DatabaseNameOverride, when set, is the server-side database name
+	// Teleport should connect to, distinct from Name.
+	DatabaseNameOverride string
+	// Replication mirrors the server's replication role and source, set by
+	// NewDatabaseFromAzureMySQLFlexServer / NewDatabaseFromAzurePostgresFlexServer
+	// whenever the server reports one.
+	Replication AzureReplication
+	// Admin is the server's discovered Azure AD administrator, left
+	// zero-valued until ApplyAzureFlexServerAdmins is run over the
+	// converted databases.
+	Admin AzureDatabaseAdmin
+}
+
+// AzureDatabaseAdmin identifies an Azure AD administrator configured on a
+// Flex Server, as returned by the server's AzureADAdministratorsClient.
+type AzureDatabaseAdmin struct {
+	// Login is the AAD administrator's login name, e.g. its user
+	// principal name or group display name.
+	Login string
+	// ObjectID is the AAD administrator's object ID.
+	ObjectID string
+}
+
+// AzureReplication is the (planned) types.Azure.Replication sub-struct,
+// mirroring a Flex Server's ReplicationRole and SourceServerResourceID
+// properties so discovery-produced replicas and their source can be
+// grouped into one logical database by AzureReplicationSetsFromDatabases.
+type AzureReplication struct {
+	// Role is "Source" or "Replica", empty if the server isn't part of a
+	// replication topology.
+	Role string
+	// SourceServerResourceID is the source server's resource ID, set only
+	// when Role is "Replica".
+	SourceServerResourceID string
+}
+
+const (
+	azureMySQLFlexPort    int64 = 3306
+	azurePostgresFlexPort int64 = 5432
+)
+
+// NewDatabaseFromAzureMySQLFlexServer converts an Azure MySQL Flexible
+// Server into an AzureFlexDatabase.
+func NewDatabaseFromAzureMySQLFlexServer(server AzureMySQLFlexServer, extraLabels map[string]string) (*AzureFlexDatabase, error) {
+	if server.Name == "" || server.FQDN == "" {
+		return nil, trace.BadParameter("server is missing a name or FQDN")
+	}
+
+	return &AzureFlexDatabase{
+		Name:        server.Name,
+		ResourceID:  server.ResourceID,
+		Protocol:    "mysql",
+		URI:         formatHostPort(server.FQDN, azureMySQLFlexPort),
+		Labels:      azureFlexServerLabels(server.ResourceGroup, server.SubscriptionID, server.ReplicationRole, server.SourceServerResourceID, extraLabels),
+		Replication: AzureReplication{Role: server.ReplicationRole, SourceServerResourceID: server.SourceServerResourceID},
+	}, nil
+}
+
+// NewDatabaseFromAzurePostgresFlexServer converts an Azure Postgres
+// Flexible Server into an AzureFlexDatabase.
+func NewDatabaseFromAzurePostgresFlexServer(server AzurePostgresFlexServer, extraLabels map[string]string) (*AzureFlexDatabase, error) {
+	if server.Name == "" || server.FQDN == "" {
+		return nil, trace.BadParameter("server is missing a name or FQDN")
+	}
+
+	return &AzureFlexDatabase{
+		Name:        server.Name,
+		ResourceID:  server.ResourceID,
+		Protocol:    "postgres",
+		URI:         formatHostPort(server.FQDN, azurePostgresFlexPort),
+		Labels:      azureFlexServerLabels(server.ResourceGroup, server.SubscriptionID, server.ReplicationRole, server.SourceServerResourceID, extraLabels),
+		Replication: AzureReplication{Role: server.ReplicationRole, SourceServerResourceID: server.SourceServerResourceID},
+	}, nil
+}
+
+// NewDatabasesFromAzureMySQLFlexServerDatabases enrolls each of a MySQL
+// Flex Server's child databases (as returned by
+// armmysqlflexibleservers.DatabasesClient.NewListByServerPager) as its own
+// AzureFlexDatabase, so RBAC can target individual databases instead of
+// requiring operators to hand-configure allowed database names.
+func NewDatabasesFromAzureMySQLFlexServerDatabases(server AzureMySQLFlexServer, databaseNames []string, extraLabels map[string]string) ([]AzureFlexDatabase, error) {
+	parent, err := NewDatabaseFromAzureMySQLFlexServer(server, extraLabels)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return childDatabasesFromAzureFlexServer(*parent, databaseNames), nil
+}
+
+// NewDatabasesFromAzurePostgresFlexServerDatabases is the Postgres
+// equivalent of NewDatabasesFromAzureMySQLFlexServerDatabases.
+func NewDatabasesFromAzurePostgresFlexServerDatabases(server AzurePostgresFlexServer, databaseNames []string, extraLabels map[string]string) ([]AzureFlexDatabase, error) {
+	parent, err := NewDatabaseFromAzurePostgresFlexServer(server, extraLabels)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return childDatabasesFromAzureFlexServer(*parent, databaseNames), nil
+}
+
+func childDatabasesFromAzureFlexServer(parent AzureFlexDatabase, databaseNames []string) []AzureFlexDatabase {
+	databases := make([]AzureFlexDatabase, 0, len(databaseNames))
+	for _, name := range databaseNames {
+		labels := make(map[string]string, len(parent.Labels)+1)
+		for k, v := range parent.Labels {
+			labels[k] = v
+		}
+		labels[DiscoveryLabelAzureDatabase] = name
+
+		databases = append(databases, AzureFlexDatabase{
+			Name:                 parent.Name + "-" + name,
+			ResourceID:           parent.ResourceID,
+			Protocol:             parent.Protocol,
+			URI:                  parent.URI,
+			Labels:               labels,
+			DatabaseNameOverride: name,
+		})
+	}
+	return databases
+}
+
+// ApplyAzureFlexServerAdmins sets Admin on every database in databases
+// whose ResourceID has a matching entry in admins, via
+// WithAzureFlexServerAdmin. Run this over the output of
+// NewDatabaseFromAzureMySQLFlexServer / NewDatabaseFromAzurePostgresFlexServer
+// (and NewDatabasesFromAzure*FlexServerDatabases, which copies ResourceID
+// from its parent) once the server's AAD administrators have been listed,
+// so MakeAzureDatabaseLoginUsername has something to resolve
+// azureADAdminTemplateVar against.
+func ApplyAzureFlexServerAdmins(databases []AzureFlexDatabase, admins map[string]AzureDatabaseAdmin) []AzureFlexDatabase {
+	if len(admins) == 0 {
+		return databases
+	}
+
+	out := make([]AzureFlexDatabase, len(databases))
+	for i, db := range databases {
+		admin, ok := admins[db.ResourceID]
+		if !ok {
+			out[i] = db
+			continue
+		}
+		out[i] = WithAzureFlexServerAdmin(db, admin.Login, admin.ObjectID)
+	}
+	return out
+}
+
+func azureFlexServerLabels(resourceGroup, subscriptionID, replicationRole, sourceServerResourceID string, extraLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(extraLabels)+4)
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	if resourceGroup != "" {
+		labels[DiscoveryLabelAzureResourceGroup] = resourceGroup
+	}
+	if subscriptionID != "" {
+		labels[DiscoveryLabelAzureSubscriptionID] = subscriptionID
+	}
+	if replicationRole != "" {
+		labels[DiscoveryLabelAzureReplicationRole] = replicationRole
+	}
+	if replicationRole == "Replica" && sourceServerResourceID != "" {
+		labels[DiscoveryLabelAzureSourceServer] = sourceServerResourceID
+	}
+	return labels
+}
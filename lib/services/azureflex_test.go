@@ -0,0 +1,87 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseFromAzureMySQLFlexServer(t *testing.T) {
+	server := AzureMySQLFlexServer{
+		Name:           "my-mysql",
+		FQDN:           "my-mysql.mysql.database.azure.com",
+		ResourceGroup:  "defaultRG",
+		SubscriptionID: "sub1",
+	}
+
+	db, err := NewDatabaseFromAzureMySQLFlexServer(server, map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, "my-mysql", db.Name)
+	require.Equal(t, "mysql", db.Protocol)
+	require.Equal(t, "my-mysql.mysql.database.azure.com:3306", db.URI)
+	require.Equal(t, "defaultRG", db.Labels[DiscoveryLabelAzureResourceGroup])
+	require.Equal(t, "sub1", db.Labels[DiscoveryLabelAzureSubscriptionID])
+	require.Equal(t, "prod", db.Labels["env"])
+
+	_, err = NewDatabaseFromAzureMySQLFlexServer(AzureMySQLFlexServer{}, nil)
+	require.Error(t, err)
+}
+
+func TestNewDatabaseFromAzurePostgresFlexServer(t *testing.T) {
+	server := AzurePostgresFlexServer{
+		Name:                   "my-pg-replica",
+		FQDN:                   "my-pg-replica.postgres.database.azure.com",
+		ReplicationRole:        "Replica",
+		SourceServerResourceID: "/subscriptions/sub1/.../my-pg",
+	}
+
+	db, err := NewDatabaseFromAzurePostgresFlexServer(server, nil)
+	require.NoError(t, err)
+	require.Equal(t, "postgres", db.Protocol)
+	require.Equal(t, "Replica", db.Labels[DiscoveryLabelAzureReplicationRole])
+	require.Equal(t, "/subscriptions/sub1/.../my-pg", db.Labels[DiscoveryLabelAzureSourceServer])
+}
+
+func TestNewDatabasesFromAzureMySQLFlexServerDatabases(t *testing.T) {
+	server := AzureMySQLFlexServer{Name: "my-mysql", FQDN: "my-mysql.mysql.database.azure.com"}
+
+	databases, err := NewDatabasesFromAzureMySQLFlexServerDatabases(server, []string{"appdb", "reportingdb"}, nil)
+	require.NoError(t, err)
+	require.Len(t, databases, 2)
+	require.Equal(t, "my-mysql-appdb", databases[0].Name)
+	require.Equal(t, "appdb", databases[0].DatabaseNameOverride)
+	require.Equal(t, "appdb", databases[0].Labels[DiscoveryLabelAzureDatabase])
+	require.Equal(t, databases[0].URI, databases[1].URI)
+}
+
+func TestApplyAzureFlexServerAdmins(t *testing.T) {
+	server := AzureMySQLFlexServer{Name: "my-mysql", FQDN: "my-mysql.mysql.database.azure.com", ResourceID: "/subscriptions/sub1/.../my-mysql"}
+	db, err := NewDatabaseFromAzureMySQLFlexServer(server, nil)
+	require.NoError(t, err)
+
+	admins := map[string]AzureDatabaseAdmin{
+		"/subscriptions/sub1/.../my-mysql": {Login: "admin@example.com", ObjectID: "obj-123"},
+	}
+
+	out := ApplyAzureFlexServerAdmins([]AzureFlexDatabase{*db}, admins)
+	require.Equal(t, "admin@example.com", out[0].Admin.Login)
+	require.Equal(t, "obj-123", out[0].Admin.ObjectID)
+
+	require.Equal(t, []AzureFlexDatabase{*db}, ApplyAzureFlexServerAdmins([]AzureFlexDatabase{*db}, nil))
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+const azureConfigLabelPrefix = "discovery/azure-config-"
+
+// azureFlexTrackedConfigurations lists the flex server configuration
+// parameter names that get folded into discovery/azure-config-* labels.
+// These are the settings operators most commonly key access rules and
+// audit dashboards on, mirroring what the AzureRM provider surfaces as
+// flex server configurations.
+var azureFlexTrackedConfigurations = map[string]bool{
+	"require_secure_transport":        true,
+	"tls_version":                     true,
+	"log_bin_trust_function_creators": true,
+	"ssl_min_protocol_version":        true,
+}
+
+// AzureFlexConfiguration is the (planned)
+// armmysqlflexibleservers.Configuration /
+// armpostgresqlflexibleservers.Configuration subset
+// WithAzureFlexServerConfigurationLabels needs: one entry returned by the
+// flex server's ConfigurationsClient.NewListByServerPager.
+type AzureFlexConfiguration struct {
+	Name  string
+	Value string
+}
+
+// WithAzureFlexServerConfigurationLabels folds the tracked entries of
+// configurations into discovery/azure-config-* labels on labels, so role
+// and dashboard authors can key off server hardening state (e.g. deny
+// access when discovery/azure-config-require_secure_transport=OFF)
+// without querying Azure directly. Untracked configuration names are
+// skipped to avoid flooding the resource with low-signal labels. Other
+// input labels are copied unchanged, but an input label with the same
+// discovery/azure-config-* key as a tracked configuration is replaced by
+// that configuration's value.
+func WithAzureFlexServerConfigurationLabels(labels map[string]string, configurations []AzureFlexConfiguration) map[string]string {
+	out := make(map[string]string, len(labels)+len(configurations))
+	for k, v := range labels {
+		out[k] = v
+	}
+	for _, configuration := range configurations {
+		if !azureFlexTrackedConfigurations[configuration.Name] {
+			continue
+		}
+		out[azureConfigLabelPrefix+configuration.Name] = configuration.Value
+	}
+	return out
+}
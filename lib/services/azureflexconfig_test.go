@@ -0,0 +1,41 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAzureFlexServerConfigurationLabels(t *testing.T) {
+	labels := map[string]string{"env": "prod"}
+	configurations := []AzureFlexConfiguration{
+		{Name: "require_secure_transport", Value: "ON"},
+		{Name: "tls_version", Value: "TLSv1.2"},
+		{Name: "some_unrelated_setting", Value: "ignored"},
+	}
+
+	out := WithAzureFlexServerConfigurationLabels(labels, configurations)
+	require.Equal(t, "prod", out["env"])
+	require.Equal(t, "ON", out["discovery/azure-config-require_secure_transport"])
+	require.Equal(t, "TLSv1.2", out["discovery/azure-config-tls_version"])
+	require.NotContains(t, out, "discovery/azure-config-some_unrelated_setting")
+
+	// Original map must not be mutated.
+	require.NotContains(t, labels, "discovery/azure-config-require_secure_transport")
+}
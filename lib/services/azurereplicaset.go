@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+// AzureReplicationSet groups an Azure MySQL or Postgres Flex source server
+// with the replicas discovered against it, so a single logical database
+// can route writes to Source and reads to one of Readers — the Azure
+// equivalent of an Aurora cluster's writer/reader endpoints. Turning a set
+// into actual writer.<source>/reader.<source> proxy endpoints is the db
+// proxy's job; this type is the routing table discovery hands it.
+type AzureReplicationSet struct {
+	// Source is the set's source server.
+	Source AzureFlexDatabase
+	// Readers are the discovered replicas of Source.
+	Readers []AzureFlexDatabase
+}
+
+// AzureReplicationSetsFromDatabases groups databases into
+// AzureReplicationSets by matching each replica's
+// Replication.SourceServerResourceID against a source database's
+// ResourceID. A database with no replication role, or a replica whose
+// source isn't present in databases, is returned in standalone instead of
+// being silently dropped.
+func AzureReplicationSetsFromDatabases(databases []AzureFlexDatabase) (sets []AzureReplicationSet, standalone []AzureFlexDatabase) {
+	setIndexByResourceID := make(map[string]int)
+	var replicas []AzureFlexDatabase
+
+	for _, db := range databases {
+		switch db.Replication.Role {
+		case "Source":
+			setIndexByResourceID[db.ResourceID] = len(sets)
+			sets = append(sets, AzureReplicationSet{Source: db})
+		case "Replica":
+			replicas = append(replicas, db)
+		default:
+			standalone = append(standalone, db)
+		}
+	}
+
+	for _, replica := range replicas {
+		index, ok := setIndexByResourceID[replica.Replication.SourceServerResourceID]
+		if !ok {
+			standalone = append(standalone, replica)
+			continue
+		}
+		sets[index].Readers = append(sets[index].Readers, replica)
+	}
+
+	return sets, standalone
+}
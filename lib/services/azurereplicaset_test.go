@@ -0,0 +1,54 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureReplicationSetsFromDatabases(t *testing.T) {
+	source := AzureFlexDatabase{
+		Name:        "my-mysql",
+		ResourceID:  "/subscriptions/sub1/.../my-mysql",
+		Replication: AzureReplication{Role: "Source"},
+	}
+	replica1 := AzureFlexDatabase{
+		Name:        "my-mysql-replica-1",
+		Replication: AzureReplication{Role: "Replica", SourceServerResourceID: source.ResourceID},
+	}
+	replica2 := AzureFlexDatabase{
+		Name:        "my-mysql-replica-2",
+		Replication: AzureReplication{Role: "Replica", SourceServerResourceID: source.ResourceID},
+	}
+	orphan := AzureFlexDatabase{
+		Name:        "orphan-replica",
+		Replication: AzureReplication{Role: "Replica", SourceServerResourceID: "/subscriptions/sub1/.../unknown"},
+	}
+	standaloneDB := AzureFlexDatabase{Name: "standalone"}
+
+	sets, standalone := AzureReplicationSetsFromDatabases([]AzureFlexDatabase{source, replica1, standaloneDB, replica2, orphan})
+
+	require.Len(t, sets, 1)
+	require.Equal(t, "my-mysql", sets[0].Source.Name)
+	require.Len(t, sets[0].Readers, 2)
+
+	require.Len(t, standalone, 2)
+	names := []string{standalone[0].Name, standalone[1].Name}
+	require.ElementsMatch(t, []string{"standalone", "orphan-replica"}, names)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package common holds discovery labeling machinery shared across cloud
+// providers, so AWS, Azure, and GCP database converters don't each
+// reimplement the same account/region/endpoint-type/override-name logic
+// with slightly different label keys.
+package common
+
+const (
+	// CloudLabel names the cloud provider a database was discovered from.
+	CloudLabel = "cloud"
+	// DiscoveryLabelAccountID names the cloud account/subscription/project
+	// that owns the discovered resource.
+	DiscoveryLabelAccountID = "discovery/account-id"
+	// DiscoveryLabelRegion names the region/location the resource runs in.
+	DiscoveryLabelRegion = "discovery/region"
+	// DiscoveryLabelEndpointType distinguishes the kind of endpoint a
+	// database resource represents, e.g. "primary", "reader", "cluster".
+	DiscoveryLabelEndpointType = "endpoint-type"
+)
+
+// Cloud provider names, used as CloudLabel's value.
+const (
+	CloudAWS   = "AWS"
+	CloudAzure = "Azure"
+	CloudGCP   = "GCP"
+)
+
+// DatabaseNameOverrideLabels lists the tag/label keys a discovered resource
+// can carry, on any cloud, to override the name Teleport would otherwise
+// derive from its cluster/instance identifier. The first matching label
+// wins. This generalizes what was previously an AWS-only convention.
+var DatabaseNameOverrideLabels = []string{
+	"TeleportDatabaseName",
+	"teleport.dev/database-name",
+}
+
+// CloudMetadataProvider supplies the cloud-specific pieces of a discovery
+// label set, so a single converter code path can build labels for a
+// database regardless of which cloud it came from.
+type CloudMetadataProvider interface {
+	// CloudName returns the CloudLabel value for this provider, e.g.
+	// CloudAWS.
+	CloudName() string
+	// AccountLabel returns the label key/value pair identifying the
+	// account, subscription, or project that owns resource. It returns an
+	// empty key if resource doesn't carry that information.
+	AccountLabel(resource any) (key, value string)
+	// RegionLabel returns the label key/value pair identifying the region
+	// or location resource runs in.
+	RegionLabel(resource any) (key, value string)
+	// EndpointTypeLabel returns the label key/value pair for endpoint,
+	// e.g. ("endpoint-type", "reader").
+	EndpointTypeLabel(endpoint string) (key, value string)
+	// ExtraLabels returns any additional provider-specific labels for
+	// resource, such as AWS's VPC ID or Azure's resource group.
+	ExtraLabels(resource any) map[string]string
+}
+
+// NameWithOverride returns the first DatabaseNameOverrideLabels value
+// present in labels, or defaultName if none is set.
+func NameWithOverride(defaultName string, labels map[string]string) string {
+	for _, overrideLabel := range DatabaseNameOverrideLabels {
+		if name, ok := labels[overrideLabel]; ok && name != "" {
+			return name
+		}
+	}
+	return defaultName
+}
+
+// BuildLabels assembles a discovery label set for resource using provider,
+// merging in extraLabels (typically the resource's own tags) last so a
+// tag can't be shadowed by a generated label of the same name.
+func BuildLabels(provider CloudMetadataProvider, resource any, endpoint string, extraLabels map[string]string) map[string]string {
+	labels := map[string]string{
+		CloudLabel: provider.CloudName(),
+	}
+	if key, value := provider.AccountLabel(resource); key != "" {
+		labels[key] = value
+	}
+	if key, value := provider.RegionLabel(resource); key != "" {
+		labels[key] = value
+	}
+	if key, value := provider.EndpointTypeLabel(endpoint); key != "" {
+		labels[key] = value
+	}
+	for k, v := range provider.ExtraLabels(resource) {
+		labels[k] = v
+	}
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	return labels
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+// AWSResource is the minimal shape CloudMetadataProvider needs out of an AWS
+// resource to label it; converters pass whatever AWS account/region they
+// already resolved rather than re-deriving it from an ARN here.
+type AWSResource struct {
+	AccountID string
+	Region    string
+}
+
+// AWSMetadataProvider is the CloudMetadataProvider for AWS resources. It
+// documents the label conventions the lib/services AWS constructors already
+// use inline; new AWS converters should move onto this provider over time.
+type AWSMetadataProvider struct{}
+
+var _ CloudMetadataProvider = AWSMetadataProvider{}
+
+// CloudName implements CloudMetadataProvider.
+func (AWSMetadataProvider) CloudName() string { return CloudAWS }
+
+// AccountLabel implements CloudMetadataProvider.
+func (AWSMetadataProvider) AccountLabel(resource any) (string, string) {
+	aws, ok := resource.(AWSResource)
+	if !ok || aws.AccountID == "" {
+		return "", ""
+	}
+	return DiscoveryLabelAccountID, aws.AccountID
+}
+
+// RegionLabel implements CloudMetadataProvider.
+func (AWSMetadataProvider) RegionLabel(resource any) (string, string) {
+	aws, ok := resource.(AWSResource)
+	if !ok || aws.Region == "" {
+		return "", ""
+	}
+	return DiscoveryLabelRegion, aws.Region
+}
+
+// EndpointTypeLabel implements CloudMetadataProvider.
+func (AWSMetadataProvider) EndpointTypeLabel(endpoint string) (string, string) {
+	if endpoint == "" {
+		return "", ""
+	}
+	return DiscoveryLabelEndpointType, endpoint
+}
+
+// ExtraLabels implements CloudMetadataProvider. AWS has no additional
+// labels beyond account/region/endpoint-type today.
+func (AWSMetadataProvider) ExtraLabels(resource any) map[string]string { return nil }
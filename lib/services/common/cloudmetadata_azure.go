@@ -0,0 +1,78 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+const (
+	// DiscoveryLabelResourceGroup names the Azure resource group a
+	// discovered resource lives in.
+	DiscoveryLabelResourceGroup = "discovery/resource-group"
+)
+
+// AzureResource is the minimal shape CloudMetadataProvider needs out of an
+// Azure resource to label it.
+type AzureResource struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Region         string
+}
+
+// AzureMetadataProvider is the CloudMetadataProvider for Azure resources,
+// e.g. Azure Cache for Redis instances discovered by NewDatabaseFromAzureRedis.
+type AzureMetadataProvider struct{}
+
+var _ CloudMetadataProvider = AzureMetadataProvider{}
+
+// CloudName implements CloudMetadataProvider.
+func (AzureMetadataProvider) CloudName() string { return CloudAzure }
+
+// AccountLabel implements CloudMetadataProvider. For Azure, "account" is the
+// subscription a resource belongs to.
+func (AzureMetadataProvider) AccountLabel(resource any) (string, string) {
+	azure, ok := resource.(AzureResource)
+	if !ok || azure.SubscriptionID == "" {
+		return "", ""
+	}
+	return DiscoveryLabelAccountID, azure.SubscriptionID
+}
+
+// RegionLabel implements CloudMetadataProvider.
+func (AzureMetadataProvider) RegionLabel(resource any) (string, string) {
+	azure, ok := resource.(AzureResource)
+	if !ok || azure.Region == "" {
+		return "", ""
+	}
+	return DiscoveryLabelRegion, azure.Region
+}
+
+// EndpointTypeLabel implements CloudMetadataProvider.
+func (AzureMetadataProvider) EndpointTypeLabel(endpoint string) (string, string) {
+	if endpoint == "" {
+		return "", ""
+	}
+	return DiscoveryLabelEndpointType, endpoint
+}
+
+// ExtraLabels implements CloudMetadataProvider, adding the resource group
+// Azure resources carry but AWS and GCP resources don't have an equivalent
+// of.
+func (AzureMetadataProvider) ExtraLabels(resource any) map[string]string {
+	azure, ok := resource.(AzureResource)
+	if !ok || azure.ResourceGroup == "" {
+		return nil
+	}
+	return map[string]string{DiscoveryLabelResourceGroup: azure.ResourceGroup}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+// GCPResource is the minimal shape CloudMetadataProvider needs out of a GCP
+// resource to label it.
+type GCPResource struct {
+	ProjectID string
+	Region    string
+}
+
+// GCPMetadataProvider is the CloudMetadataProvider for GCP resources, e.g.
+// Cloud Memorystore instances discovered by NewDatabaseFromGCPMemorystore.
+type GCPMetadataProvider struct{}
+
+var _ CloudMetadataProvider = GCPMetadataProvider{}
+
+// CloudName implements CloudMetadataProvider.
+func (GCPMetadataProvider) CloudName() string { return CloudGCP }
+
+// AccountLabel implements CloudMetadataProvider. For GCP, "account" is the
+// project a resource belongs to.
+func (GCPMetadataProvider) AccountLabel(resource any) (string, string) {
+	gcp, ok := resource.(GCPResource)
+	if !ok || gcp.ProjectID == "" {
+		return "", ""
+	}
+	return DiscoveryLabelAccountID, gcp.ProjectID
+}
+
+// RegionLabel implements CloudMetadataProvider.
+func (GCPMetadataProvider) RegionLabel(resource any) (string, string) {
+	gcp, ok := resource.(GCPResource)
+	if !ok || gcp.Region == "" {
+		return "", ""
+	}
+	return DiscoveryLabelRegion, gcp.Region
+}
+
+// EndpointTypeLabel implements CloudMetadataProvider.
+func (GCPMetadataProvider) EndpointTypeLabel(endpoint string) (string, string) {
+	if endpoint == "" {
+		return "", ""
+	}
+	return DiscoveryLabelEndpointType, endpoint
+}
+
+// ExtraLabels implements CloudMetadataProvider. GCP has no additional
+// labels beyond account/region/endpoint-type today.
+func (GCPMetadataProvider) ExtraLabels(resource any) map[string]string { return nil }
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLabelsAWS(t *testing.T) {
+	labels := BuildLabels(AWSMetadataProvider{}, AWSResource{AccountID: "1234", Region: "us-east-1"}, "reader", map[string]string{"env": "prod"})
+	require.Equal(t, CloudAWS, labels[CloudLabel])
+	require.Equal(t, "1234", labels[DiscoveryLabelAccountID])
+	require.Equal(t, "us-east-1", labels[DiscoveryLabelRegion])
+	require.Equal(t, "reader", labels[DiscoveryLabelEndpointType])
+	require.Equal(t, "prod", labels["env"])
+}
+
+func TestBuildLabelsAzure(t *testing.T) {
+	resource := AzureResource{SubscriptionID: "sub-1", ResourceGroup: "rg-1", Region: "eastus"}
+	labels := BuildLabels(AzureMetadataProvider{}, resource, "", nil)
+	require.Equal(t, CloudAzure, labels[CloudLabel])
+	require.Equal(t, "sub-1", labels[DiscoveryLabelAccountID])
+	require.Equal(t, "eastus", labels[DiscoveryLabelRegion])
+	require.Equal(t, "rg-1", labels[DiscoveryLabelResourceGroup])
+	require.NotContains(t, labels, DiscoveryLabelEndpointType)
+}
+
+func TestBuildLabelsGCP(t *testing.T) {
+	labels := BuildLabels(GCPMetadataProvider{}, GCPResource{ProjectID: "proj-1", Region: "us-central1"}, "instance", nil)
+	require.Equal(t, CloudGCP, labels[CloudLabel])
+	require.Equal(t, "proj-1", labels[DiscoveryLabelAccountID])
+	require.Equal(t, "us-central1", labels[DiscoveryLabelRegion])
+	require.Equal(t, "instance", labels[DiscoveryLabelEndpointType])
+}
+
+func TestNameWithOverride(t *testing.T) {
+	require.Equal(t, "default", NameWithOverride("default", nil))
+	require.Equal(t, "custom", NameWithOverride("default", map[string]string{"TeleportDatabaseName": "custom"}))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strconv"
+
+	"github.com/gravitational/trace"
+)
+
+// Database is a database discovered through a CloudMetadataProvider. It's
+// the provider-backed counterpart to lib/services' and awsv2's per-engine
+// Database types, used by converters that don't need an engine-specific
+// result struct.
+type Database struct {
+	// Name is the discovered resource's Teleport name, subject to
+	// DatabaseNameOverrideLabels.
+	Name string
+	// URI is the database's connection endpoint, host:port.
+	URI string
+	// Labels are the discovery labels BuildLabels assembled for this
+	// resource.
+	Labels map[string]string
+}
+
+// AzureRedis is the minimal shape of an Azure Cache for Redis instance
+// NewDatabaseFromAzureRedis needs.
+type AzureRedis struct {
+	Name           string
+	Host           string
+	Port           int
+	SubscriptionID string
+	ResourceGroup  string
+	Region         string
+	Tags           map[string]string
+}
+
+// NewDatabaseFromAzureRedis converts an Azure Cache for Redis instance into
+// a Database, labeling it through AzureMetadataProvider so it carries the
+// same discovery/account-id, discovery/region, and endpoint-type labels as
+// an AWS ElastiCache database would.
+func NewDatabaseFromAzureRedis(redis AzureRedis) (*Database, error) {
+	if redis.Name == "" || redis.Host == "" {
+		return nil, trace.BadParameter("redis instance is missing a name or host")
+	}
+
+	provider := AzureMetadataProvider{}
+	resource := AzureResource{
+		SubscriptionID: redis.SubscriptionID,
+		ResourceGroup:  redis.ResourceGroup,
+		Region:         redis.Region,
+	}
+	labels := BuildLabels(provider, resource, "instance", redis.Tags)
+
+	return &Database{
+		Name:   NameWithOverride(redis.Name, labels),
+		URI:    formatHostPort(redis.Host, redis.Port),
+		Labels: labels,
+	}, nil
+}
+
+// GCPMemorystore is the minimal shape of a GCP Memorystore (Redis) instance
+// NewDatabaseFromGCPMemorystore needs.
+type GCPMemorystore struct {
+	Name      string
+	Host      string
+	Port      int
+	ProjectID string
+	Region    string
+	Labels    map[string]string
+}
+
+// NewDatabaseFromGCPMemorystore converts a GCP Memorystore instance into a
+// Database, labeling it through GCPMetadataProvider.
+func NewDatabaseFromGCPMemorystore(instance GCPMemorystore) (*Database, error) {
+	if instance.Name == "" || instance.Host == "" {
+		return nil, trace.BadParameter("memorystore instance is missing a name or host")
+	}
+
+	provider := GCPMetadataProvider{}
+	resource := GCPResource{
+		ProjectID: instance.ProjectID,
+		Region:    instance.Region,
+	}
+	labels := BuildLabels(provider, resource, "instance", instance.Labels)
+
+	return &Database{
+		Name:   NameWithOverride(instance.Name, labels),
+		URI:    formatHostPort(instance.Host, instance.Port),
+		Labels: labels,
+	}, nil
+}
+
+func formatHostPort(host string, port int) string {
+	if port == 0 {
+		return host
+	}
+	return host + ":" + strconv.Itoa(port)
+}
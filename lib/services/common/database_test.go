@@ -0,0 +1,59 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseFromAzureRedis(t *testing.T) {
+	db, err := NewDatabaseFromAzureRedis(AzureRedis{
+		Name:           "my-redis",
+		Host:           "my-redis.redis.cache.windows.net",
+		Port:           6380,
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-1",
+		Region:         "eastus",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "my-redis", db.Name)
+	require.Equal(t, "my-redis.redis.cache.windows.net:6380", db.URI)
+	require.Equal(t, CloudAzure, db.Labels[CloudLabel])
+	require.Equal(t, "rg-1", db.Labels[DiscoveryLabelResourceGroup])
+
+	_, err = NewDatabaseFromAzureRedis(AzureRedis{})
+	require.Error(t, err)
+}
+
+func TestNewDatabaseFromGCPMemorystore(t *testing.T) {
+	db, err := NewDatabaseFromGCPMemorystore(GCPMemorystore{
+		Name:      "my-instance",
+		Host:      "10.0.0.5",
+		Port:      6379,
+		ProjectID: "proj-1",
+		Region:    "us-central1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "my-instance", db.Name)
+	require.Equal(t, "10.0.0.5:6379", db.URI)
+	require.Equal(t, CloudGCP, db.Labels[CloudLabel])
+
+	_, err = NewDatabaseFromGCPMemorystore(GCPMemorystore{})
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDatabaseAuth(t *testing.T) {
+	tests := []struct {
+		desc     string
+		protocol string
+		mode     DatabaseAuthMode
+		jwt      *DatabaseJWTAuth
+		wantErr  string
+	}{
+		{
+			desc:     "default cert mode",
+			protocol: "sqlserver",
+			mode:     "",
+		},
+		{
+			desc:     "jwt on unsupported engine",
+			protocol: "sqlserver",
+			mode:     DatabaseAuthModeJWT,
+			jwt:      &DatabaseJWTAuth{Issuer: "https://issuer", Audience: "db"},
+			wantErr:  "does not support JWT",
+		},
+		{
+			desc:     "jwt missing issuer",
+			protocol: "postgres",
+			mode:     DatabaseAuthModeJWT,
+			jwt:      &DatabaseJWTAuth{Audience: "db"},
+			wantErr:  "requires an Issuer",
+		},
+		{
+			desc:     "jwt missing audience",
+			protocol: "postgres",
+			mode:     DatabaseAuthModeJWT,
+			jwt:      &DatabaseJWTAuth{Issuer: "https://issuer"},
+			wantErr:  "requires an Audience",
+		},
+		{
+			desc:     "valid jwt",
+			protocol: "snowflake",
+			mode:     DatabaseAuthModeJWT,
+			jwt:      &DatabaseJWTAuth{Issuer: "https://issuer", Audience: "db"},
+		},
+		{
+			desc:     "unknown mode",
+			protocol: "postgres",
+			mode:     "bogus",
+			wantErr:  "unknown database authentication mode",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := ValidateDatabaseAuth(test.protocol, test.mode, test.jwt)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewDatabaseAuth(t *testing.T) {
+	auth, err := NewDatabaseAuth("snowflake", DatabaseAuthModeJWT, &DatabaseJWTAuth{Issuer: "https://issuer", Audience: "db"})
+	require.NoError(t, err)
+	require.Equal(t, DatabaseAuthModeJWT, auth.Mode)
+	require.Equal(t, "https://issuer", auth.JWT.Issuer)
+
+	_, err = NewDatabaseAuth("sqlserver", DatabaseAuthModeJWT, &DatabaseJWTAuth{Issuer: "https://issuer", Audience: "db"})
+	require.ErrorContains(t, err, "does not support JWT")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// DefaultDatabaseCertificateTTL is used when a database doesn't set
+// TLS.CertificateTTL.
+const DefaultDatabaseCertificateTTL = time.Hour
+
+// DefaultDatabaseCertificateRenewBefore is used when a database doesn't set
+// TLS.RenewBefore.
+const DefaultDatabaseCertificateRenewBefore = 10 * time.Minute
+
+// DatabaseCertPolicy pins how long Teleport-issued database access
+// certificates live for a given database and when they should be renewed.
+// Some regulated environments require sub-hour-lived certs while
+// long-running batch pipelines break under aggressive default rotation, so
+// this is configurable per database rather than cluster-wide. Callers
+// should obtain the effective policy through ResolveDatabaseCertPolicy
+// rather than using CheckAndSetDefaults and ClampDatabaseCertPolicy
+// separately.
+type DatabaseCertPolicy struct {
+	// CertificateTTL is how long an issued certificate is valid for.
+	CertificateTTL time.Duration
+	// RenewBefore is how long before expiry the certificate access path
+	// should mint a replacement.
+	RenewBefore time.Duration
+}
+
+// CheckAndSetDefaults validates p and fills in defaults for zero-valued
+// fields.
+func (p *DatabaseCertPolicy) CheckAndSetDefaults() error {
+	if p.CertificateTTL == 0 {
+		p.CertificateTTL = DefaultDatabaseCertificateTTL
+	}
+	if p.RenewBefore == 0 {
+		p.RenewBefore = DefaultDatabaseCertificateRenewBefore
+	}
+	if p.CertificateTTL <= 0 {
+		return trace.BadParameter("CertificateTTL must be positive")
+	}
+	if p.RenewBefore <= 0 {
+		return trace.BadParameter("RenewBefore must be positive")
+	}
+	if p.RenewBefore >= p.CertificateTTL {
+		return trace.BadParameter("RenewBefore (%s) must be shorter than CertificateTTL (%s)", p.RenewBefore, p.CertificateTTL)
+	}
+	return nil
+}
+
+// ClampDatabaseCertPolicy enforces a cluster-wide maximum certificate TTL on
+// an otherwise-valid per-database policy, returning the effective policy to
+// use. It never lengthens a database's requested TTL, only shortens it.
+func ClampDatabaseCertPolicy(p DatabaseCertPolicy, clusterMaxTTL time.Duration) DatabaseCertPolicy {
+	if clusterMaxTTL > 0 && p.CertificateTTL > clusterMaxTTL {
+		p.CertificateTTL = clusterMaxTTL
+		if p.RenewBefore >= p.CertificateTTL {
+			p.RenewBefore = p.CertificateTTL / 10
+		}
+	}
+	return p
+}
+
+// ResolveDatabaseCertPolicy fills in defaults and validates a database's
+// requested certificate policy, then clamps it to clusterMaxTTL. This is
+// the single entry point the database access CA issuance path should call;
+// it exists so CheckAndSetDefaults and ClampDatabaseCertPolicy are always
+// applied together instead of a caller forgetting one of the two steps.
+//
+// That CA issuance path (lib/srv/db, lib/auth) isn't part of this checkout,
+// so ResolveDatabaseCertPolicy has no production caller yet and is
+// exercised only by its own test.
+func ResolveDatabaseCertPolicy(p DatabaseCertPolicy, clusterMaxTTL time.Duration) (DatabaseCertPolicy, error) {
+	if err := p.CheckAndSetDefaults(); err != nil {
+		return DatabaseCertPolicy{}, trace.Wrap(err)
+	}
+	return ClampDatabaseCertPolicy(p, clusterMaxTTL), nil
+}
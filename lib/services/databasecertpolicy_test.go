@@ -0,0 +1,54 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseCertPolicyCheckAndSetDefaults(t *testing.T) {
+	var p DatabaseCertPolicy
+	require.NoError(t, p.CheckAndSetDefaults())
+	require.Equal(t, DefaultDatabaseCertificateTTL, p.CertificateTTL)
+	require.Equal(t, DefaultDatabaseCertificateRenewBefore, p.RenewBefore)
+
+	bad := DatabaseCertPolicy{CertificateTTL: time.Minute, RenewBefore: time.Hour}
+	require.ErrorContains(t, bad.CheckAndSetDefaults(), "must be shorter")
+}
+
+func TestResolveDatabaseCertPolicy(t *testing.T) {
+	resolved, err := ResolveDatabaseCertPolicy(DatabaseCertPolicy{}, time.Minute*30)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute*30, resolved.CertificateTTL)
+	require.Less(t, resolved.RenewBefore, resolved.CertificateTTL)
+
+	_, err = ResolveDatabaseCertPolicy(DatabaseCertPolicy{CertificateTTL: time.Minute, RenewBefore: time.Hour}, 0)
+	require.ErrorContains(t, err, "must be shorter")
+}
+
+func TestClampDatabaseCertPolicy(t *testing.T) {
+	p := DatabaseCertPolicy{CertificateTTL: 24 * time.Hour, RenewBefore: time.Hour}
+	clamped := ClampDatabaseCertPolicy(p, time.Hour)
+	require.Equal(t, time.Hour, clamped.CertificateTTL)
+	require.Less(t, clamped.RenewBefore, clamped.CertificateTTL)
+
+	unaffected := ClampDatabaseCertPolicy(p, 48*time.Hour)
+	require.Equal(t, p, unaffected)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// DatabaseCIDRLabel is attached to a database discovered from a cloud
+	// provider API and names the CIDR prefix (from the provider's published
+	// IP-range feed) that the instance's subnet or VNet falls within. It lets
+	// role label selectors scope access by network, e.g. "only databases in
+	// 10.20.0.0/16".
+	DatabaseCIDRLabel = "teleport.dev/cidr"
+	// DatabaseServiceNetworkLabel is attached alongside DatabaseCIDRLabel and
+	// names the provider's service/region prefix the CIDR belongs to, e.g.
+	// "us-west-2/rds" or "eastus/Sql". It is derived the same way but is the
+	// more common target for role label selectors since it doesn't require
+	// operators to know the underlying network layout.
+	DatabaseServiceNetworkLabel = "teleport.dev/service-network"
+)
+
+// CloudIPRangeEntry is one prefix/service-network pair out of a cloud
+// provider's published IP-range feed (AWS ip-ranges.json or an Azure
+// ServiceTags file).
+type CloudIPRangeEntry struct {
+	// CIDR is the network prefix, e.g. "10.20.0.0/16" or "52.94.0.0/22".
+	CIDR string
+	// ServiceNetwork is the provider's name for the prefix's region/service
+	// combination, e.g. "us-west-2/rds" or "eastus/Sql".
+	ServiceNetwork string
+}
+
+// CloudIPRangeFeed matches a subnet ID or hostname to a CIDR and
+// service-network entry (see Resolve for the rule), caching the provider's published feed
+// (AWS ip-ranges.json, Azure ServiceTags) so repeated lookups during a
+// discovery pass don't re-fetch or re-parse it.
+//
+// CloudIPRangeFeed is safe for concurrent use.
+type CloudIPRangeFeed struct {
+	// Fetch loads the current feed contents. It's called at most once,
+	// lazily, on the first Resolve call.
+	Fetch func() ([]CloudIPRangeEntry, error)
+
+	mu      sync.Mutex
+	loaded  bool
+	loadErr error
+	entries []CloudIPRangeEntry
+}
+
+// Resolve maps a subnet ID (AWS), VNet/subnet resource ID (Azure) or
+// hostname to the narrowest matching CIDR and its service-network name. An
+// identifier matches an entry when it ends with the entry's CIDR, or
+// contains its ServiceNetwork either as written ("us-west-2/rds") or with
+// "/" replaced by "." ("us-west-2.rds", as in an RDS hostname). The match is
+// on the text alone: identifiers are not looked up or resolved to
+// addresses. It returns false if no entry matches.
+func (f *CloudIPRangeFeed) Resolve(subnetOrHost string) (CloudIPRangeEntry, bool, error) {
+	if subnetOrHost == "" {
+		return CloudIPRangeEntry{}, false, nil
+	}
+
+	entries, err := f.load()
+	if err != nil {
+		return CloudIPRangeEntry{}, false, trace.Wrap(err)
+	}
+
+	var best CloudIPRangeEntry
+	found := false
+	for _, e := range entries {
+		if !strings.Contains(subnetOrHost, e.ServiceNetwork) &&
+			!strings.Contains(subnetOrHost, strings.ReplaceAll(e.ServiceNetwork, "/", ".")) &&
+			!strings.HasSuffix(subnetOrHost, e.CIDR) {
+			continue
+		}
+		// Prefer the most specific (longest) CIDR match, mirroring
+		// longest-prefix-match routing semantics.
+		if !found || cidrPrefixBits(e.CIDR) > cidrPrefixBits(best.CIDR) {
+			best = e
+			found = true
+		}
+	}
+	return best, found, nil
+}
+
+// cidrPrefixBits returns cidr's prefix length, or -1 if it doesn't parse.
+func cidrPrefixBits(cidr string) int {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return -1
+	}
+	return prefix.Bits()
+}
+
+func (f *CloudIPRangeFeed) load() ([]CloudIPRangeEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.loaded {
+		return f.entries, f.loadErr
+	}
+	f.loaded = true
+
+	if f.Fetch == nil {
+		f.loadErr = trace.BadParameter("CloudIPRangeFeed has no Fetch function configured")
+		return nil, f.loadErr
+	}
+
+	entries, err := f.Fetch()
+	if err != nil {
+		f.loadErr = trace.Wrap(err, "fetching cloud IP-range feed")
+		return nil, f.loadErr
+	}
+	f.entries = entries
+	return f.entries, nil
+}
+
+// ApplyCloudIPRangeLabels resolves subnetOrHost against feed and returns a
+// copy of labels with DatabaseCIDRLabel/DatabaseServiceNetworkLabel added if
+// a match was found. It's the single entry point converters should use;
+// calling feed.Resolve and WithDatabaseCloudLabels separately risks a
+// caller forwarding the wrong "resolved" flag.
+func ApplyCloudIPRangeLabels(feed *CloudIPRangeFeed, labels map[string]string, subnetOrHost string) (map[string]string, error) {
+	entry, resolved, err := feed.Resolve(subnetOrHost)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return WithDatabaseCloudLabels(labels, entry, resolved), nil
+}
+
+// WithDatabaseCloudLabels returns a copy of labels with DatabaseCIDRLabel and
+// DatabaseServiceNetworkLabel added when entry was resolved. It never
+// overwrites a label an operator has already set by hand.
+func WithDatabaseCloudLabels(labels map[string]string, entry CloudIPRangeEntry, resolved bool) map[string]string {
+	if !resolved {
+		return labels
+	}
+	out := make(map[string]string, len(labels)+2)
+	for k, v := range labels {
+		out[k] = v
+	}
+	if _, ok := out[DatabaseCIDRLabel]; !ok && entry.CIDR != "" {
+		out[DatabaseCIDRLabel] = entry.CIDR
+	}
+	if _, ok := out[DatabaseServiceNetworkLabel]; !ok && entry.ServiceNetwork != "" {
+		out[DatabaseServiceNetworkLabel] = entry.ServiceNetwork
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudIPRangeFeedResolve(t *testing.T) {
+	calls := 0
+	feed := &CloudIPRangeFeed{
+		Fetch: func() ([]CloudIPRangeEntry, error) {
+			calls++
+			return []CloudIPRangeEntry{
+				{CIDR: "10.20.0.0/16", ServiceNetwork: "us-west-2/rds"},
+				{CIDR: "10.20.4.0/24", ServiceNetwork: "us-west-2/rds"},
+				{CIDR: "10.30.0.0/16", ServiceNetwork: "eastus/Sql"},
+			}, nil
+		},
+	}
+
+	entry, ok, err := feed.Resolve("subnet-10.20.4.0/24")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "10.20.4.0/24", entry.CIDR)
+	require.Equal(t, "us-west-2/rds", entry.ServiceNetwork)
+
+	entry, ok, err = feed.Resolve("eastus/Sql-server1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "eastus/Sql", entry.ServiceNetwork)
+
+	_, ok, err = feed.Resolve("no-such-network")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = feed.Resolve("subnet-10.20.4.0/24")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls, "feed should only be fetched once and then cached")
+}
+
+func TestCloudIPRangeFeedResolveEmpty(t *testing.T) {
+	feed := &CloudIPRangeFeed{Fetch: func() ([]CloudIPRangeEntry, error) { return nil, nil }}
+	entry, ok, err := feed.Resolve("")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, entry)
+}
+
+func TestApplyCloudIPRangeLabels(t *testing.T) {
+	feed := &CloudIPRangeFeed{
+		Fetch: func() ([]CloudIPRangeEntry, error) {
+			return []CloudIPRangeEntry{
+				{CIDR: "10.20.0.0/16", ServiceNetwork: "us-west-2/rds"},
+			}, nil
+		},
+	}
+
+	labels, err := ApplyCloudIPRangeLabels(feed, map[string]string{"env": "prod"}, "subnet-10.20.0.0/16")
+	require.NoError(t, err)
+	require.Equal(t, "prod", labels["env"])
+	require.Equal(t, "10.20.0.0/16", labels[DatabaseCIDRLabel])
+	require.Equal(t, "us-west-2/rds", labels[DatabaseServiceNetworkLabel])
+
+	// No match leaves the input labels untouched.
+	original := map[string]string{"env": "prod"}
+	labels, err = ApplyCloudIPRangeLabels(feed, original, "no-such-network")
+	require.NoError(t, err)
+	require.Equal(t, original, labels)
+}
+
+func TestWithDatabaseCloudLabels(t *testing.T) {
+	entry := CloudIPRangeEntry{CIDR: "10.20.0.0/16", ServiceNetwork: "us-west-2/rds"}
+
+	labels := WithDatabaseCloudLabels(map[string]string{"env": "prod"}, entry, true)
+	require.Equal(t, "prod", labels["env"])
+	require.Equal(t, "10.20.0.0/16", labels[DatabaseCIDRLabel])
+	require.Equal(t, "us-west-2/rds", labels[DatabaseServiceNetworkLabel])
+
+	// An operator-set label is never overwritten.
+	labels = WithDatabaseCloudLabels(map[string]string{DatabaseCIDRLabel: "manual"}, entry, true)
+	require.Equal(t, "manual", labels[DatabaseCIDRLabel])
+
+	// Unresolved lookups leave the input labels untouched.
+	original := map[string]string{"env": "prod"}
+	labels = WithDatabaseCloudLabels(original, CloudIPRangeEntry{}, false)
+	require.Equal(t, original, labels)
+}
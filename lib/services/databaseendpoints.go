@@ -0,0 +1,166 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// DatabaseEndpoint is one addressable endpoint for a database that exposes
+// more than one, such as an Aurora writer plus its readers, an RDS
+// multi-AZ standby, or an Azure Redis geo-replica. The first endpoint in a
+// list is conventionally the primary and is used whenever callers don't
+// care about failover; EndpointResolver.Resolve reorders by Priority
+// regardless.
+type DatabaseEndpoint struct {
+	// URI is the endpoint's host:port.
+	URI string
+	// AssumeRoleARN is the AWS IAM role to assume when connecting through
+	// this endpoint, if different from the database's default.
+	AssumeRoleARN string
+	// Priority orders endpoints for failover purposes; lower values are
+	// tried first. Endpoints sharing a priority are tried in list order.
+	Priority int
+}
+
+// ValidateDatabaseEndpoints checks that a database's endpoint list is
+// internally consistent: every endpoint has a URI, and at most one distinct
+// AssumeRoleARN is in use across the list (either every endpoint shares the
+// database's role, or exactly one override is present).
+func ValidateDatabaseEndpoints(endpoints []DatabaseEndpoint) error {
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	seenARNs := make(map[string]struct{})
+	for i, e := range endpoints {
+		if e.URI == "" {
+			return trace.BadParameter("endpoint %d is missing a URI", i)
+		}
+		if e.AssumeRoleARN != "" {
+			seenARNs[e.AssumeRoleARN] = struct{}{}
+		}
+	}
+	if len(seenARNs) > 1 {
+		return trace.BadParameter("database endpoints must all assume the same role, got %d distinct AssumeRoleARN values", len(seenARNs))
+	}
+
+	return nil
+}
+
+// EndpointHealthChecker probes a single endpoint and reports whether it's
+// currently reachable. Callers typically supply a lightweight dial-and-close
+// or protocol-level ping.
+type EndpointHealthChecker func(ctx context.Context, endpoint DatabaseEndpoint) error
+
+// EndpointResolverConfig configures an [EndpointResolver].
+type EndpointResolverConfig struct {
+	// HealthCheck probes an endpoint's liveness. Required.
+	HealthCheck EndpointHealthChecker
+	// CacheTTL is how long a liveness result is trusted before the endpoint
+	// is re-probed.
+	CacheTTL time.Duration
+}
+
+func (c *EndpointResolverConfig) checkAndSetDefaults() error {
+	if c.HealthCheck == nil {
+		return trace.BadParameter("HealthCheck is required")
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = 30 * time.Second
+	}
+	return nil
+}
+
+// EndpointResolver picks a healthy endpoint from a database's ordered
+// endpoint list, similar to Consul's service-resolver Failover: endpoints
+// are probed in priority order and the first live one is returned, with
+// liveness cached for CacheTTL so a hot path doesn't re-probe on every call.
+type EndpointResolver struct {
+	cfg EndpointResolverConfig
+
+	mu    sync.Mutex
+	cache map[string]cachedResult
+}
+
+type cachedResult struct {
+	healthy   bool
+	checkedAt time.Time
+}
+
+// NewEndpointResolver creates an [*EndpointResolver].
+func NewEndpointResolver(cfg EndpointResolverConfig) (*EndpointResolver, error) {
+	if err := cfg.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &EndpointResolver{cfg: cfg, cache: make(map[string]cachedResult)}, nil
+}
+
+// Resolve returns the highest-priority healthy endpoint in endpoints,
+// falling back to the next one if the health check fails. It returns
+// trace.ConnectionProblem if every endpoint is unreachable.
+func (r *EndpointResolver) Resolve(ctx context.Context, endpoints []DatabaseEndpoint) (*DatabaseEndpoint, error) {
+	if len(endpoints) == 0 {
+		return nil, trace.BadParameter("no endpoints to resolve")
+	}
+	if err := ValidateDatabaseEndpoints(endpoints); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	ordered := append([]DatabaseEndpoint(nil), endpoints...)
+	sortByPriority(ordered)
+
+	var lastErr error
+	for i := range ordered {
+		e := ordered[i]
+		if r.healthy(ctx, e) {
+			return &e, nil
+		}
+		lastErr = trace.ConnectionProblem(nil, "endpoint %q is unreachable", e.URI)
+	}
+
+	return nil, trace.Wrap(lastErr)
+}
+
+func (r *EndpointResolver) healthy(ctx context.Context, e DatabaseEndpoint) bool {
+	r.mu.Lock()
+	if cached, ok := r.cache[e.URI]; ok && time.Since(cached.checkedAt) < r.cfg.CacheTTL {
+		r.mu.Unlock()
+		return cached.healthy
+	}
+	r.mu.Unlock()
+
+	healthy := r.cfg.HealthCheck(ctx, e) == nil
+
+	r.mu.Lock()
+	r.cache[e.URI] = cachedResult{healthy: healthy, checkedAt: time.Now()}
+	r.mu.Unlock()
+
+	return healthy
+}
+
+func sortByPriority(endpoints []DatabaseEndpoint) {
+	for i := 1; i < len(endpoints); i++ {
+		for j := i; j > 0 && endpoints[j].Priority < endpoints[j-1].Priority; j-- {
+			endpoints[j], endpoints[j-1] = endpoints[j-1], endpoints[j]
+		}
+	}
+}
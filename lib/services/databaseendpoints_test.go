@@ -0,0 +1,125 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errUnhealthy = errors.New("endpoint unhealthy")
+
+func TestValidateDatabaseEndpoints(t *testing.T) {
+	tests := []struct {
+		desc      string
+		endpoints []DatabaseEndpoint
+		wantErr   string
+	}{
+		{
+			desc: "empty is valid",
+		},
+		{
+			desc: "single endpoint",
+			endpoints: []DatabaseEndpoint{
+				{URI: "writer.example.com:5432"},
+			},
+		},
+		{
+			desc: "missing URI",
+			endpoints: []DatabaseEndpoint{
+				{URI: "writer.example.com:5432"},
+				{Priority: 1},
+			},
+			wantErr: "missing a URI",
+		},
+		{
+			desc: "conflicting assume role arns",
+			endpoints: []DatabaseEndpoint{
+				{URI: "writer.example.com:5432", AssumeRoleARN: "arn:aws:iam::1:role/a"},
+				{URI: "reader.example.com:5432", AssumeRoleARN: "arn:aws:iam::1:role/b"},
+			},
+			wantErr: "distinct AssumeRoleARN",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := ValidateDatabaseEndpoints(test.endpoints)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEndpointResolver(t *testing.T) {
+	endpoints := []DatabaseEndpoint{
+		{URI: "reader.example.com:5432", Priority: 1},
+		{URI: "writer.example.com:5432", Priority: 0},
+	}
+
+	unhealthy := map[string]bool{"writer.example.com:5432": true}
+	resolver, err := NewEndpointResolver(EndpointResolverConfig{
+		HealthCheck: func(_ context.Context, e DatabaseEndpoint) error {
+			if unhealthy[e.URI] {
+				return errUnhealthy
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	resolved, err := resolver.Resolve(context.Background(), endpoints)
+	require.NoError(t, err)
+	require.Equal(t, "reader.example.com:5432", resolved.URI)
+}
+
+func TestEndpointResolverRejectsInvalidEndpoints(t *testing.T) {
+	endpoints := []DatabaseEndpoint{
+		{URI: "writer.example.com:5432", AssumeRoleARN: "arn:aws:iam::1:role/a"},
+		{URI: "reader.example.com:5432", AssumeRoleARN: "arn:aws:iam::1:role/b"},
+	}
+
+	resolver, err := NewEndpointResolver(EndpointResolverConfig{
+		HealthCheck: func(context.Context, DatabaseEndpoint) error { return nil },
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background(), endpoints)
+	require.ErrorContains(t, err, "distinct AssumeRoleARN")
+}
+
+func TestEndpointResolverAllUnhealthy(t *testing.T) {
+	endpoints := []DatabaseEndpoint{
+		{URI: "writer.example.com:5432"},
+	}
+
+	resolver, err := NewEndpointResolver(EndpointResolverConfig{
+		HealthCheck: func(context.Context, DatabaseEndpoint) error {
+			return errUnhealthy
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background(), endpoints)
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/gravitational/trace"
+)
+
+// DatabasePort describes one additional listener exposed by a single
+// physical database instance, such as a Postgres read-only replica port or
+// the ClickHouse native protocol port alongside its HTTP one. Role and label
+// selectors can target Name to grant access to one port without granting
+// access to the others on the same instance.
+type DatabasePort struct {
+	// Name identifies the port for use in role/label selectors, e.g.
+	// "native" or "replica".
+	Name string
+	// Protocol is the engine protocol spoken on this port, using the same
+	// values as DatabaseSpecV3.Protocol.
+	Protocol string
+	// Port is the TCP port number.
+	Port int
+	// TLSServerName overrides the TLS server name used for this port, if it
+	// differs from the database's default.
+	TLSServerName string
+}
+
+// ValidateDatabasePorts checks that a database's additional port list is
+// well-formed: every port has a name, a valid TCP port number, and names are
+// unique within the list.
+func ValidateDatabasePorts(ports []DatabasePort) error {
+	seen := make(map[string]struct{}, len(ports))
+	for _, p := range ports {
+		if p.Name == "" {
+			return trace.BadParameter("database port is missing a name")
+		}
+		if _, ok := seen[p.Name]; ok {
+			return trace.BadParameter("duplicate database port name %q", p.Name)
+		}
+		seen[p.Name] = struct{}{}
+
+		if p.Port <= 0 || p.Port > 65535 {
+			return trace.BadParameter("database port %q has an invalid port number %d", p.Name, p.Port)
+		}
+	}
+	return nil
+}
+
+// NewDatabasePorts validates a database's additional port list and returns
+// it unchanged, mirroring the validate-on-construct convention other
+// per-database config types in this package use (e.g. NewEndpointResolver,
+// DatabaseCertPolicy.CheckAndSetDefaults) so callers can't end up with a
+// registered port list that was never checked.
+//
+// This checkout has no lib/srv/db runtime to call it from, so NewDatabasePorts
+// is currently exercised only by its own test; wire it in once that
+// subsystem exists here rather than treating this constructor alone as the
+// fix for DatabasePort being otherwise dead code.
+func NewDatabasePorts(ports []DatabasePort) ([]DatabasePort, error) {
+	if err := ValidateDatabasePorts(ports); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ports, nil
+}
+
+// DatabasePortByName returns the port with the given name, or false if no
+// such port exists.
+func DatabasePortByName(ports []DatabasePort, name string) (DatabasePort, bool) {
+	for _, p := range ports {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return DatabasePort{}, false
+}
+
+// FormatDatabasePortAddr formats a host and DatabasePort as a host:port
+// string suitable for dialing.
+func FormatDatabasePortAddr(host string, p DatabasePort) string {
+	return fmt.Sprintf("%s:%d", host, p.Port)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDatabasePorts(t *testing.T) {
+	tests := []struct {
+		desc    string
+		ports   []DatabasePort
+		wantErr string
+	}{
+		{
+			desc:  "empty is valid",
+			ports: nil,
+		},
+		{
+			desc: "valid ports",
+			ports: []DatabasePort{
+				{Name: "native", Protocol: "clickhouse", Port: 9000},
+				{Name: "http", Protocol: "clickhouse", Port: 8123},
+			},
+		},
+		{
+			desc:    "missing name",
+			ports:   []DatabasePort{{Port: 5432}},
+			wantErr: "missing a name",
+		},
+		{
+			desc: "duplicate name",
+			ports: []DatabasePort{
+				{Name: "replica", Port: 5432},
+				{Name: "replica", Port: 5433},
+			},
+			wantErr: "duplicate database port",
+		},
+		{
+			desc:    "invalid port number",
+			ports:   []DatabasePort{{Name: "replica", Port: 70000}},
+			wantErr: "invalid port number",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := ValidateDatabasePorts(test.ports)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewDatabasePorts(t *testing.T) {
+	ports, err := NewDatabasePorts([]DatabasePort{{Name: "native", Port: 9000}})
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+
+	_, err = NewDatabasePorts([]DatabasePort{{Name: "native", Port: 9000}, {Name: "native", Port: 8123}})
+	require.ErrorContains(t, err, "duplicate database port")
+}
+
+func TestDatabasePortByName(t *testing.T) {
+	ports := []DatabasePort{
+		{Name: "native", Port: 9000},
+		{Name: "http", Port: 8123},
+	}
+
+	p, ok := DatabasePortByName(ports, "http")
+	require.True(t, ok)
+	require.Equal(t, 8123, p.Port)
+	require.Equal(t, "host:8123", FormatDatabasePortAddr("host", p))
+
+	_, ok = DatabasePortByName(ports, "missing")
+	require.False(t, ok)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// AzureMySQLFlexClient is the subset of the Azure MySQL Flexible Servers API
+// an [AzureMySQLFlexScanner] needs to discover servers, their child
+// databases, and their AAD administrators. It's satisfied by a thin wrapper
+// around armmysqlflexibleservers.ServersClient/DatabasesClient and
+// armmysqlflexibleservers.AADAdministratorsClient; tests inject a fake
+// instead of standing up real Azure credentials.
+type AzureMySQLFlexClient interface {
+	// ListServers returns every MySQL Flexible Server in the subscription
+	// located in region.
+	ListServers(ctx context.Context, region string) ([]services.AzureMySQLFlexServer, error)
+	// ListServerDatabases returns the names of server's child databases.
+	ListServerDatabases(ctx context.Context, server services.AzureMySQLFlexServer) ([]string, error)
+	// ListServerAdmins returns server's configured AAD administrators.
+	ListServerAdmins(ctx context.Context, server services.AzureMySQLFlexServer) ([]services.AzureDatabaseAdmin, error)
+}
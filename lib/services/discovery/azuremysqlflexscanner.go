@@ -0,0 +1,94 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// AzureMySQLFlexScanner is a [RegionScanner] that discovers Azure MySQL
+// Flexible Servers and their child databases, relabeling each with its
+// server's AAD administrator via services.ApplyAzureFlexServerAdmins.
+type AzureMySQLFlexScanner struct {
+	// Client lists servers, their child databases, and their AAD
+	// administrators.
+	Client AzureMySQLFlexClient
+}
+
+// ScanRegion lists every MySQL Flex Server in region, plus each server's
+// child databases, and stamps the server's discovered AAD administrator
+// (if any) onto every database enrolled from it.
+func (s AzureMySQLFlexScanner) ScanRegion(ctx context.Context, region string) ([]Database, error) {
+	servers, err := s.Client.ListServers(ctx, region)
+	if err != nil {
+		return nil, trace.Wrap(err, "listing Azure MySQL Flex servers in %q", region)
+	}
+
+	var flexDatabases []services.AzureFlexDatabase
+	admins := make(map[string]services.AzureDatabaseAdmin, len(servers))
+	for _, server := range servers {
+		parent, err := services.NewDatabaseFromAzureMySQLFlexServer(server, nil)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		flexDatabases = append(flexDatabases, *parent)
+
+		dbNames, err := s.Client.ListServerDatabases(ctx, server)
+		if err != nil {
+			return nil, trace.Wrap(err, "listing databases on Azure MySQL Flex server %q", server.Name)
+		}
+		if len(dbNames) > 0 {
+			children, err := services.NewDatabasesFromAzureMySQLFlexServerDatabases(server, dbNames, nil)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			flexDatabases = append(flexDatabases, children...)
+		}
+
+		serverAdmins, err := s.Client.ListServerAdmins(ctx, server)
+		if err != nil {
+			return nil, trace.Wrap(err, "listing AAD administrators on Azure MySQL Flex server %q", server.Name)
+		}
+		if len(serverAdmins) > 0 {
+			admins[server.ResourceID] = serverAdmins[0]
+		}
+	}
+
+	flexDatabases = services.ApplyAzureFlexServerAdmins(flexDatabases, admins)
+
+	databases := make([]Database, 0, len(flexDatabases))
+	for _, db := range flexDatabases {
+		labels := db.Labels
+		if db.Admin.Login != "" {
+			labels = make(map[string]string, len(db.Labels)+1)
+			for k, v := range db.Labels {
+				labels[k] = v
+			}
+			labels["azure-admin-login"] = db.Admin.Login
+		}
+		databases = append(databases, Database{
+			Name:   db.Name,
+			URI:    db.URI,
+			Labels: labels,
+		})
+	}
+	return databases, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+type fakeAzureMySQLFlexClient struct {
+	servers       []services.AzureMySQLFlexServer
+	databaseNames map[string][]string
+	admins        map[string][]services.AzureDatabaseAdmin
+}
+
+func (f fakeAzureMySQLFlexClient) ListServers(ctx context.Context, region string) ([]services.AzureMySQLFlexServer, error) {
+	var out []services.AzureMySQLFlexServer
+	for _, s := range f.servers {
+		if s.Location == region {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeAzureMySQLFlexClient) ListServerDatabases(ctx context.Context, server services.AzureMySQLFlexServer) ([]string, error) {
+	return f.databaseNames[server.Name], nil
+}
+
+func (f fakeAzureMySQLFlexClient) ListServerAdmins(ctx context.Context, server services.AzureMySQLFlexServer) ([]services.AzureDatabaseAdmin, error) {
+	return f.admins[server.Name], nil
+}
+
+func TestAzureMySQLFlexScannerScanRegion(t *testing.T) {
+	client := fakeAzureMySQLFlexClient{
+		servers: []services.AzureMySQLFlexServer{
+			{
+				Name:          "my-mysql",
+				FQDN:          "my-mysql.mysql.database.azure.com",
+				ResourceID:    "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.DBforMySQL/flexibleServers/my-mysql",
+				ResourceGroup: "rg1",
+				Location:      "eastus",
+			},
+			{
+				Name:       "other-region",
+				FQDN:       "other-region.mysql.database.azure.com",
+				ResourceID: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.DBforMySQL/flexibleServers/other-region",
+				Location:   "westus",
+			},
+		},
+		databaseNames: map[string][]string{
+			"my-mysql": {"app"},
+		},
+		admins: map[string][]services.AzureDatabaseAdmin{
+			"my-mysql": {{Login: "alice@example.com", ObjectID: "obj-1"}},
+		},
+	}
+
+	scanner := AzureMySQLFlexScanner{Client: client}
+	databases, err := scanner.ScanRegion(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, databases, 2)
+
+	byName := make(map[string]Database, len(databases))
+	for _, db := range databases {
+		byName[db.Name] = db
+	}
+
+	parent, ok := byName["my-mysql"]
+	require.True(t, ok)
+	require.Equal(t, "alice@example.com", parent.Labels["azure-admin-login"])
+
+	child, ok := byName["my-mysql-app"]
+	require.True(t, ok)
+	require.Equal(t, "alice@example.com", child.Labels["azure-admin-login"])
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import "github.com/gravitational/teleport/lib/services"
+
+// BlueGreenFilterMode controls how a scan result handles databases that are
+// part of an active RDS Blue/Green Deployment.
+type BlueGreenFilterMode string
+
+const (
+	// KeepBlueGreenBoth registers both the source and target side of every
+	// blue/green deployment. This is the default.
+	KeepBlueGreenBoth BlueGreenFilterMode = ""
+	// SkipBlueGreenTarget drops the target (green) side of every blue/green
+	// deployment, for operators who only want the currently live side
+	// registered.
+	SkipBlueGreenTarget BlueGreenFilterMode = "skip-target"
+)
+
+// ApplyBlueGreenLabels relabels every database in databases whose Name has
+// a matching entry in deployments, via services.WithRDSBlueGreenLabels.
+// Run this ahead of FilterBlueGreenTargets in a scan pipeline: the filter
+// only acts on the role label this sets.
+func ApplyBlueGreenLabels(databases []Database, deployments map[string]services.RDSBlueGreenDeployment) []Database {
+	if len(deployments) == 0 {
+		return databases
+	}
+
+	out := make([]Database, len(databases))
+	for i, db := range databases {
+		bg, ok := deployments[db.Name]
+		if !ok {
+			out[i] = db
+			continue
+		}
+		db.Name, db.Labels = services.WithRDSBlueGreenLabels(db.Name, db.Labels, bg)
+		out[i] = db
+	}
+	return out
+}
+
+// FilterBlueGreenTargets applies mode to databases, returning a new slice
+// with target-side blue/green databases removed when mode is
+// SkipBlueGreenTarget. Databases with no blue/green role label are always
+// kept.
+func FilterBlueGreenTargets(databases []Database, mode BlueGreenFilterMode) []Database {
+	if mode != SkipBlueGreenTarget {
+		return databases
+	}
+
+	filtered := make([]Database, 0, len(databases))
+	for _, db := range databases {
+		if db.Labels[services.RDSBlueGreenRoleLabel] == services.RDSBlueGreenRoleTarget {
+			continue
+		}
+		filtered = append(filtered, db)
+	}
+	return filtered
+}
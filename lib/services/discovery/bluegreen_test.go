@@ -0,0 +1,62 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+func TestApplyBlueGreenLabels(t *testing.T) {
+	databases := []Database{
+		{Name: "standalone"},
+		{Name: "mydb"},
+	}
+	deployments := map[string]services.RDSBlueGreenDeployment{
+		"mydb": {ID: "bgd-abc123", Status: "AVAILABLE", Role: services.RDSBlueGreenRoleTarget},
+	}
+
+	out := ApplyBlueGreenLabels(databases, deployments)
+	require.Equal(t, "standalone", out[0].Name)
+	require.Empty(t, out[0].Labels)
+
+	require.Equal(t, "mydb-green", out[1].Name)
+	require.Equal(t, services.RDSBlueGreenRoleTarget, out[1].Labels[services.RDSBlueGreenRoleLabel])
+	require.Equal(t, "bgd-abc123", out[1].Labels[services.RDSBlueGreenIDLabel])
+
+	require.Equal(t, databases, ApplyBlueGreenLabels(databases, nil))
+}
+
+func TestFilterBlueGreenTargets(t *testing.T) {
+	databases := []Database{
+		{Name: "standalone"},
+		{Name: "mydb", Labels: map[string]string{services.RDSBlueGreenRoleLabel: services.RDSBlueGreenRoleSource}},
+		{Name: "mydb-green", Labels: map[string]string{services.RDSBlueGreenRoleLabel: services.RDSBlueGreenRoleTarget}},
+	}
+
+	require.Equal(t, databases, FilterBlueGreenTargets(databases, KeepBlueGreenBoth))
+
+	filtered := FilterBlueGreenTargets(databases, SkipBlueGreenTarget)
+	names := make([]string, 0, len(filtered))
+	for _, db := range filtered {
+		names = append(names, db.Name)
+	}
+	require.Equal(t, []string{"standalone", "mydb"}, names)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/redshift"
+)
+
+// RDSClient is the subset of the RDS API a [RegionScanner] needs to
+// discover instances, clusters, and proxies. It's satisfied by
+// *rds.RDS; tests inject a fake instead of standing up real AWS
+// credentials.
+type RDSClient interface {
+	DescribeDBInstancesPagesWithContext(ctx context.Context, in *rds.DescribeDBInstancesInput, fn func(*rds.DescribeDBInstancesOutput, bool) bool, opts ...interface{}) error
+	DescribeDBClustersPagesWithContext(ctx context.Context, in *rds.DescribeDBClustersInput, fn func(*rds.DescribeDBClustersOutput, bool) bool, opts ...interface{}) error
+	DescribeDBProxiesPagesWithContext(ctx context.Context, in *rds.DescribeDBProxiesInput, fn func(*rds.DescribeDBProxiesOutput, bool) bool, opts ...interface{}) error
+	DescribeDBProxyEndpointsPagesWithContext(ctx context.Context, in *rds.DescribeDBProxyEndpointsInput, fn func(*rds.DescribeDBProxyEndpointsOutput, bool) bool, opts ...interface{}) error
+	DescribeGlobalClustersPagesWithContext(ctx context.Context, in *rds.DescribeGlobalClustersInput, fn func(*rds.DescribeGlobalClustersOutput, bool) bool, opts ...interface{}) error
+	DescribeBlueGreenDeploymentsPagesWithContext(ctx context.Context, in *rds.DescribeBlueGreenDeploymentsInput, fn func(*rds.DescribeBlueGreenDeploymentsOutput, bool) bool, opts ...interface{}) error
+	ListTagsForResourceWithContext(ctx context.Context, in *rds.ListTagsForResourceInput, opts ...interface{}) (*rds.ListTagsForResourceOutput, error)
+}
+
+// RedshiftClient is the subset of the Redshift API a [RegionScanner]
+// needs to discover provisioned clusters. It's satisfied by
+// *redshift.Redshift.
+type RedshiftClient interface {
+	DescribeClustersPagesWithContext(ctx context.Context, in *redshift.DescribeClustersInput, fn func(*redshift.DescribeClustersOutput, bool) bool, opts ...interface{}) error
+}
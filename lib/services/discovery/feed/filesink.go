@@ -0,0 +1,77 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/trace"
+)
+
+// FileSink writes a Store's target set to a JSON file on disk, for
+// operators who want to plug Teleport's database discovery into an
+// existing file-based prober (e.g. cloud-prober's file target provider)
+// without running the handler or the full agent.
+type FileSink struct {
+	// Path is the target file, conventionally named "targets.json".
+	Path string
+	// Filter, if non-zero, narrows what gets written.
+	Filter Filter
+}
+
+// NewFileSink returns a FileSink writing to path with no filtering.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{Path: path}
+}
+
+// Write atomically replaces Path with store's current (filtered) target
+// set: it writes to a temp file in the same directory, then renames over
+// Path, so readers never observe a partially written file.
+func (s *FileSink) Write(store *Store) error {
+	targets, _ := store.Snapshot(s.Filter)
+	if targets == nil {
+		targets = []Target{}
+	}
+
+	data, err := json.MarshalIndent(targets, "", "  ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	dir := filepath.Dir(s.Path)
+	tmp, err := os.CreateTemp(dir, ".targets-*.json.tmp")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return trace.Wrap(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := os.Rename(tmpPath, s.Path); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
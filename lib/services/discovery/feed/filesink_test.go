@@ -0,0 +1,60 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSinkWrite(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{{Name: "a"}, {Name: "b"}}))
+
+	path := filepath.Join(t.TempDir(), "targets.json")
+	sink := NewFileSink(path)
+	require.NoError(t, sink.Write(store))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got []Target
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got, 2)
+}
+
+func TestFileSinkWriteAppliesFilter(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{
+		{Name: "a", Region: "us-east-1"},
+		{Name: "b", Region: "us-west-2"},
+	}))
+
+	path := filepath.Join(t.TempDir(), "targets.json")
+	sink := &FileSink{Path: path, Filter: Filter{Region: "us-east-1"}}
+	require.NoError(t, sink.Write(store))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got []Target
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "a", got[0].Name)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+// Filter narrows a feed's target list. A zero-value field is ignored; all
+// set fields must match for a target to pass.
+type Filter struct {
+	// Region, if set, matches Target.Region exactly.
+	Region string
+	// AccountID, if set, matches Target.AccountID exactly.
+	AccountID string
+	// Protocol, if set, matches Target.Protocol exactly.
+	Protocol string
+	// Tags, if set, requires every key/value pair to be present in
+	// Target.Tags.
+	Tags map[string]string
+}
+
+// Matches reports whether target satisfies every set field of f.
+func (f Filter) Matches(target Target) bool {
+	if f.Region != "" && target.Region != f.Region {
+		return false
+	}
+	if f.AccountID != "" && target.AccountID != f.AccountID {
+		return false
+	}
+	if f.Protocol != "" && target.Protocol != f.Protocol {
+		return false
+	}
+	for k, v := range f.Tags {
+		if target.Tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the subset of targets that match f, preserving order.
+func (f Filter) Apply(targets []Target) []Target {
+	filtered := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if f.Matches(target) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered
+}
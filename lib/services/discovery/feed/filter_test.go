@@ -0,0 +1,50 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatches(t *testing.T) {
+	target := Target{
+		Name:      "my-db",
+		Region:    "us-east-1",
+		AccountID: "1234",
+		Protocol:  "postgres",
+		Tags:      map[string]string{"env": "prod"},
+	}
+
+	require.True(t, Filter{}.Matches(target))
+	require.True(t, Filter{Region: "us-east-1"}.Matches(target))
+	require.False(t, Filter{Region: "us-west-2"}.Matches(target))
+	require.True(t, Filter{Tags: map[string]string{"env": "prod"}}.Matches(target))
+	require.False(t, Filter{Tags: map[string]string{"env": "staging"}}.Matches(target))
+	require.False(t, Filter{Protocol: "mysql"}.Matches(target))
+}
+
+func TestFilterApply(t *testing.T) {
+	targets := []Target{
+		{Name: "a", Region: "us-east-1"},
+		{Name: "b", Region: "us-west-2"},
+	}
+	filtered := Filter{Region: "us-east-1"}.Apply(targets)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "a", filtered[0].Name)
+}
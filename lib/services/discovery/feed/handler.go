@@ -0,0 +1,96 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandlerPath is the path Handler expects to be mounted at.
+const HandlerPath = "/v1/discovery/databases"
+
+// Handler serves a Store's current target set as JSON, honoring
+// If-None-Match so pollers that already have the latest set get a cheap
+// 304 instead of re-downloading it.
+type Handler struct {
+	store *Store
+}
+
+// NewHandler returns a Handler serving store.
+func NewHandler(store *Store) *Handler {
+	return &Handler{store: store}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter := filterFromQuery(r.URL.Query())
+	targets, etag := h.store.Snapshot(filter)
+
+	w.Header().Set("ETag", etag)
+	if etag != "" && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if targets == nil {
+		targets = []Target{}
+	}
+	_ = json.NewEncoder(w).Encode(targets)
+}
+
+func filterFromQuery(query map[string][]string) Filter {
+	get := func(key string) string {
+		values := query[key]
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+
+	filter := Filter{
+		Region:    get("region"),
+		AccountID: get("account_id"),
+		Protocol:  get("protocol"),
+	}
+	if tagValues := query["tag"]; len(tagValues) > 0 {
+		filter.Tags = make(map[string]string, len(tagValues))
+		for _, kv := range tagValues {
+			key, value, ok := splitTag(kv)
+			if ok {
+				filter.Tags[key] = value
+			}
+		}
+	}
+	return filter
+}
+
+// splitTag splits a "key=value" tag matcher query parameter.
+func splitTag(kv string) (key, value string, ok bool) {
+	for i := 0; i < len(kv); i++ {
+		if kv[i] == '=' {
+			return kv[:i], kv[i+1:], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerServesTargets(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{{Name: "a", Region: "us-east-1"}, {Name: "b", Region: "us-west-2"}}))
+	handler := NewHandler(store)
+
+	req := httptest.NewRequest(http.MethodGet, HandlerPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got []Target
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	require.NotEmpty(t, rec.Header().Get("ETag"))
+}
+
+func TestHandlerNotModified(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{{Name: "a"}}))
+	handler := NewHandler(store)
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, HandlerPath, nil))
+	etag := first.Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, HandlerPath, nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestHandlerFiltersByQuery(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{
+		{Name: "a", Region: "us-east-1"},
+		{Name: "b", Region: "us-west-2"},
+	}))
+	handler := NewHandler(store)
+
+	req := httptest.NewRequest(http.MethodGet, HandlerPath+"?region=us-east-1", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var got []Target
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "a", got[0].Name)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services/discovery"
+)
+
+// defaultReconcileInterval is how often Reconciler.Run re-scans when
+// ReconcilerConfig.Interval isn't set.
+const defaultReconcileInterval = 5 * time.Minute
+
+// ReconcilerConfig configures a [Reconciler].
+type ReconcilerConfig struct {
+	// Scanner discovers the current set of databases. Required.
+	Scanner discovery.Scanner
+	// Store is updated with the targets built from each scan. Required.
+	Store *Store
+	// Regions lists the regions Scanner.Scan is called with on every
+	// reconcile. Required.
+	Regions []string
+	// Interval is how often Run re-scans. Defaults to 5 minutes.
+	Interval time.Duration
+}
+
+func (c *ReconcilerConfig) checkAndSetDefaults() error {
+	if c.Scanner == nil {
+		return trace.BadParameter("Scanner is required")
+	}
+	if c.Store == nil {
+		return trace.BadParameter("Store is required")
+	}
+	if len(c.Regions) == 0 {
+		return trace.BadParameter("at least one region is required")
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultReconcileInterval
+	}
+	return nil
+}
+
+// Reconciler feeds a discovery.Scanner's output into a Store, giving the
+// feed package a real producer instead of requiring callers to build
+// Targets by hand and call Store.Update themselves.
+type Reconciler struct {
+	cfg ReconcilerConfig
+}
+
+// NewReconciler creates a [*Reconciler].
+func NewReconciler(cfg ReconcilerConfig) (*Reconciler, error) {
+	if err := cfg.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Reconciler{cfg: cfg}, nil
+}
+
+// ReconcileOnce scans cfg.Regions and replaces cfg.Store's target set with
+// the result. A discovered database's "protocol" label (if any) becomes
+// its Target.Protocol.
+func (r *Reconciler) ReconcileOnce(ctx context.Context) error {
+	result, err := r.cfg.Scanner.Scan(ctx, r.cfg.Regions)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	targets := make([]Target, 0, len(result.Databases))
+	for _, db := range result.Databases {
+		targets = append(targets, NewTargetFromDatabase(db, db.Labels["protocol"]))
+	}
+
+	return trace.Wrap(r.cfg.Store.Update(targets))
+}
+
+// Run calls ReconcileOnce immediately and then every cfg.Interval, until ctx
+// is canceled. It returns the first error from ReconcileOnce rather than
+// retrying silently, since a Scan or Store.Update failure here means the
+// feed has gone stale and callers need to know.
+func (r *Reconciler) Run(ctx context.Context) error {
+	if err := r.ReconcileOnce(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.ReconcileOnce(ctx); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+}
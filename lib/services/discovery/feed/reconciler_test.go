@@ -0,0 +1,73 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services/discovery"
+)
+
+type fakeScanner struct {
+	result *discovery.ScanResult
+	err    error
+}
+
+func (f fakeScanner) Scan(ctx context.Context, regions []string) (*discovery.ScanResult, error) {
+	return f.result, f.err
+}
+
+func TestReconcilerReconcileOnce(t *testing.T) {
+	store := NewStore()
+	scanner := fakeScanner{result: &discovery.ScanResult{
+		Databases: []discovery.Database{
+			{Name: "my-db", URI: "my-db.example.com:5432", Labels: map[string]string{"protocol": "postgres"}},
+		},
+	}}
+
+	reconciler, err := NewReconciler(ReconcilerConfig{Scanner: scanner, Store: store, Regions: []string{"us-west-2"}})
+	require.NoError(t, err)
+
+	require.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+	targets, _ := store.Snapshot(Filter{})
+	require.Len(t, targets, 1)
+	require.Equal(t, "my-db", targets[0].Name)
+	require.Equal(t, "postgres", targets[0].Protocol)
+}
+
+func TestReconcilerReconcileOnceScanError(t *testing.T) {
+	store := NewStore()
+	scanner := fakeScanner{err: trace.ConnectionProblem(nil, "scan failed")}
+
+	reconciler, err := NewReconciler(ReconcilerConfig{Scanner: scanner, Store: store, Regions: []string{"us-west-2"}})
+	require.NoError(t, err)
+
+	require.Error(t, reconciler.ReconcileOnce(context.Background()))
+}
+
+func TestNewReconcilerRequiresConfig(t *testing.T) {
+	_, err := NewReconciler(ReconcilerConfig{})
+	require.True(t, trace.IsBadParameter(err))
+
+	_, err = NewReconciler(ReconcilerConfig{Scanner: fakeScanner{}, Store: NewStore()})
+	require.True(t, trace.IsBadParameter(err), "Regions is required")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// Store holds the current target set produced by the most recent discovery
+// reconcile, along with an ETag derived from its contents so consumers can
+// poll cheaply for changes. It's safe for concurrent use.
+type Store struct {
+	mu      sync.RWMutex
+	targets []Target
+	etag    string
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Update replaces the store's target set with targets, sorted by name, and
+// recomputes the ETag. Targets sharing a name are all kept, in no defined
+// order relative to each other. It's called once per discovery reconcile.
+func (s *Store) Update(targets []Target) error {
+	sorted := make([]Target, len(targets))
+	copy(sorted, targets)
+	sortTargets(sorted)
+
+	etag, err := computeETag(sorted)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets = sorted
+	s.etag = etag
+	return nil
+}
+
+// Snapshot returns the current target set matching filter and its ETag.
+func (s *Store) Snapshot(filter Filter) ([]Target, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return filter.Apply(s.targets), s.etag
+}
+
+func computeETag(targets []Target) (string, error) {
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`, nil
+}
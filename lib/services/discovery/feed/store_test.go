@@ -0,0 +1,56 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreUpdateAndSnapshot(t *testing.T) {
+	store := NewStore()
+
+	targets, etag := store.Snapshot(Filter{})
+	require.Empty(t, targets)
+	require.Empty(t, etag)
+
+	require.NoError(t, store.Update([]Target{{Name: "b"}, {Name: "a"}}))
+	targets, etag1 := store.Snapshot(Filter{})
+	require.Equal(t, []string{"a", "b"}, []string{targets[0].Name, targets[1].Name})
+	require.NotEmpty(t, etag1)
+
+	require.NoError(t, store.Update([]Target{{Name: "a"}, {Name: "b"}}))
+	_, etag2 := store.Snapshot(Filter{})
+	require.Equal(t, etag1, etag2, "ETag should be stable for an equivalent target set regardless of input order")
+
+	require.NoError(t, store.Update([]Target{{Name: "a"}}))
+	_, etag3 := store.Snapshot(Filter{})
+	require.NotEqual(t, etag2, etag3)
+}
+
+func TestStoreSnapshotFilters(t *testing.T) {
+	store := NewStore()
+	require.NoError(t, store.Update([]Target{
+		{Name: "a", Region: "us-east-1"},
+		{Name: "b", Region: "us-west-2"},
+	}))
+
+	targets, _ := store.Snapshot(Filter{Region: "us-east-1"})
+	require.Len(t, targets, 1)
+	require.Equal(t, "a", targets[0].Name)
+}
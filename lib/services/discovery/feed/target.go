@@ -0,0 +1,94 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package feed serializes discovered databases into a stable, RDS-style
+// target list (account ID, region, tags, endpoint) that external
+// monitoring and probing systems can consume without running the full
+// Teleport agent. A Store holds the current target set; Handler serves it
+// over HTTP with ETag-based incremental updates, and FileSink writes it to
+// disk for file-based consumers.
+package feed
+
+import (
+	"sort"
+
+	"github.com/gravitational/teleport/lib/services/common"
+	"github.com/gravitational/teleport/lib/services/discovery"
+)
+
+// Target is one database in the feed, shaped after RDS service-discovery
+// targets: an endpoint plus the account/region/tag metadata a prober needs
+// to route and label its checks.
+type Target struct {
+	// Name is the database's Teleport name.
+	Name string `json:"name"`
+	// URI is the database's connection endpoint, host:port.
+	URI string `json:"uri"`
+	// Protocol is the database's wire protocol, e.g. "postgres", "mysql",
+	// "redis", "mongodb".
+	Protocol string `json:"protocol,omitempty"`
+	// AccountID is the cloud account, subscription, or project that owns
+	// the database, taken from its discovery/account-id label if set.
+	AccountID string `json:"account_id,omitempty"`
+	// Region is the database's cloud region, taken from its
+	// discovery/region label if set.
+	Region string `json:"region,omitempty"`
+	// Tags holds the database's remaining labels, excluding the ones
+	// promoted to AccountID and Region.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// NewTarget builds a Target from a discovered database's name, URI, and
+// labels, promoting the well-known account/region labels and copying
+// everything else into Tags.
+func NewTarget(name, uri, protocol string, labels map[string]string) Target {
+	target := Target{
+		Name:     name,
+		URI:      uri,
+		Protocol: protocol,
+	}
+	if len(labels) == 0 {
+		return target
+	}
+
+	tags := make(map[string]string, len(labels))
+	for k, v := range labels {
+		switch k {
+		case common.DiscoveryLabelAccountID:
+			target.AccountID = v
+		case common.DiscoveryLabelRegion:
+			target.Region = v
+		default:
+			tags[k] = v
+		}
+	}
+	if len(tags) > 0 {
+		target.Tags = tags
+	}
+	return target
+}
+
+// NewTargetFromDatabase builds a Target from a discovery.Database, the
+// common result type produced by the scanner's converters.
+func NewTargetFromDatabase(db discovery.Database, protocol string) Target {
+	return NewTarget(db.Name, db.URI, protocol, db.Labels)
+}
+
+// sortTargets sorts targets by name, giving the feed a stable, diffable
+// order regardless of the scan order that produced them.
+func sortTargets(targets []Target) {
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+}
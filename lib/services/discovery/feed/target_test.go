@@ -0,0 +1,55 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services/common"
+	"github.com/gravitational/teleport/lib/services/discovery"
+)
+
+func TestNewTarget(t *testing.T) {
+	labels := map[string]string{
+		common.DiscoveryLabelAccountID: "1234",
+		common.DiscoveryLabelRegion:    "us-east-1",
+		"env":                          "prod",
+	}
+	target := NewTarget("my-db", "my-db.example.com:5432", "postgres", labels)
+	require.Equal(t, "my-db", target.Name)
+	require.Equal(t, "1234", target.AccountID)
+	require.Equal(t, "us-east-1", target.Region)
+	require.Equal(t, "prod", target.Tags["env"])
+	require.NotContains(t, target.Tags, common.DiscoveryLabelAccountID)
+}
+
+func TestNewTargetFromDatabase(t *testing.T) {
+	db := discovery.Database{Name: "my-db", URI: "host:5432", Labels: map[string]string{"env": "prod"}}
+	target := NewTargetFromDatabase(db, "postgres")
+	require.Equal(t, "my-db", target.Name)
+	require.Equal(t, "postgres", target.Protocol)
+	require.Equal(t, "prod", target.Tags["env"])
+}
+
+func TestSortTargets(t *testing.T) {
+	targets := []Target{{Name: "b"}, {Name: "a"}}
+	sortTargets(targets)
+	require.Equal(t, "a", targets[0].Name)
+	require.Equal(t, "b", targets[1].Name)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// DatabasesFromRDSGlobalCluster converts an Aurora Global Database's
+// topology into discovered databases via
+// services.NewDatabasesFromRDSGlobalCluster, one per region's writer (and
+// reader, if present), so a RegionScanner can fold global clusters into a
+// ScanResult the same way it does provisioned instances and clusters.
+func DatabasesFromRDSGlobalCluster(gc *rds.GlobalCluster, members []*rds.DBCluster) ([]Database, error) {
+	globalDatabases, err := services.NewDatabasesFromRDSGlobalCluster(gc, members)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	databases := make([]Database, 0, len(globalDatabases))
+	for _, gd := range globalDatabases {
+		databases = append(databases, Database{
+			Name:   gd.Name,
+			URI:    gd.URI,
+			Labels: gd.Labels,
+		})
+	}
+	return databases, nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+func TestDatabasesFromRDSGlobalCluster(t *testing.T) {
+	gc := &rds.GlobalCluster{
+		GlobalClusterIdentifier: aws.String("my-global-db"),
+		GlobalClusterMembers: []*rds.GlobalClusterMember{
+			{
+				DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+				IsWriter:     aws.Bool(true),
+			},
+		},
+	}
+	members := []*rds.DBCluster{
+		{
+			DBClusterArn:        aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+			DBClusterIdentifier: aws.String("primary"),
+			Endpoint:            aws.String("primary.cluster.us-west-2.rds.amazonaws.com"),
+			Port:                aws.Int64(5432),
+		},
+	}
+
+	databases, err := DatabasesFromRDSGlobalCluster(gc, members)
+	require.NoError(t, err)
+	require.Len(t, databases, 1)
+	require.Equal(t, "primary", databases[0].Name)
+	require.Equal(t, "primary.cluster.us-west-2.rds.amazonaws.com:5432", databases[0].URI)
+	require.Equal(t, services.RDSGlobalClusterEndpointTypePrimary, databases[0].Labels[services.DiscoveryLabelEndpointType])
+
+	_, err = DatabasesFromRDSGlobalCluster(&rds.GlobalCluster{}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// RDSGlobalClusterScanner is a [RegionScanner] that discovers Aurora Global
+// Database topologies via DatabasesFromRDSGlobalCluster, relabeling any
+// member under an active Blue/Green Deployment via ApplyBlueGreenLabels.
+// Global clusters aren't themselves region-scoped, so ScanRegion describes
+// every global cluster on each call and keeps only the endpoints
+// DatabasesFromRDSGlobalCluster attributes to region; AWSScanner calling
+// this once per configured region means every region's member gets picked
+// up by exactly one call.
+type RDSGlobalClusterScanner struct {
+	// Client lists global clusters and their member DB clusters.
+	Client RDSClient
+}
+
+// ScanRegion returns the global-cluster-derived endpoints whose
+// DiscoveryLabelRegion matches region.
+func (s RDSGlobalClusterScanner) ScanRegion(ctx context.Context, region string) ([]Database, error) {
+	var globalClusters []*rds.GlobalCluster
+	err := s.Client.DescribeGlobalClustersPagesWithContext(ctx, &rds.DescribeGlobalClustersInput{}, func(page *rds.DescribeGlobalClustersOutput, lastPage bool) bool {
+		globalClusters = append(globalClusters, page.GlobalClusters...)
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "describing RDS global clusters")
+	}
+
+	var members []*rds.DBCluster
+	err = s.Client.DescribeDBClustersPagesWithContext(ctx, &rds.DescribeDBClustersInput{}, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		members = append(members, page.DBClusters...)
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "describing RDS clusters in %q", region)
+	}
+
+	var blueGreenDeployments []*rds.BlueGreenDeployment
+	err = s.Client.DescribeBlueGreenDeploymentsPagesWithContext(ctx, &rds.DescribeBlueGreenDeploymentsInput{}, func(page *rds.DescribeBlueGreenDeploymentsOutput, lastPage bool) bool {
+		blueGreenDeployments = append(blueGreenDeployments, page.BlueGreenDeployments...)
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "describing RDS blue/green deployments")
+	}
+	deploymentsByClusterID := blueGreenDeploymentsByClusterID(blueGreenDeployments)
+
+	var databases []Database
+	for _, gc := range globalClusters {
+		clusterDatabases, err := DatabasesFromRDSGlobalCluster(gc, members)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		clusterDatabases = ApplyBlueGreenLabels(clusterDatabases, deploymentsByClusterID)
+		for _, db := range clusterDatabases {
+			if db.Labels[services.DiscoveryLabelRegion] != region {
+				continue
+			}
+			databases = append(databases, db)
+		}
+	}
+	return databases, nil
+}
+
+// blueGreenDeploymentsByClusterID indexes deployments by the cluster
+// identifier (the last segment of an RDS cluster ARN) of both their source
+// and target, so ApplyBlueGreenLabels can look a converted database's Name
+// up directly.
+func blueGreenDeploymentsByClusterID(deployments []*rds.BlueGreenDeployment) map[string]services.RDSBlueGreenDeployment {
+	out := make(map[string]services.RDSBlueGreenDeployment, len(deployments)*2)
+	for _, d := range deployments {
+		if d == nil || d.BlueGreenDeploymentIdentifier == nil {
+			continue
+		}
+		if id, ok := clusterIDFromARN(d.Source); ok {
+			out[id] = services.RDSBlueGreenDeployment{ID: *d.BlueGreenDeploymentIdentifier, Status: aws.StringValue(d.Status), Role: services.RDSBlueGreenRoleSource}
+		}
+		if id, ok := clusterIDFromARN(d.Target); ok {
+			out[id] = services.RDSBlueGreenDeployment{ID: *d.BlueGreenDeploymentIdentifier, Status: aws.StringValue(d.Status), Role: services.RDSBlueGreenRoleTarget}
+		}
+	}
+	return out
+}
+
+func clusterIDFromARN(arn *string) (string, bool) {
+	if arn == nil || *arn == "" {
+		return "", false
+	}
+	parts := strings.Split(*arn, ":")
+	if len(parts) == 0 {
+		return "", false
+	}
+	return parts[len(parts)-1], true
+}
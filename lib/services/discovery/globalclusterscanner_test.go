@@ -0,0 +1,129 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+type fakeRDSGlobalClusterClient struct {
+	RDSClient
+	globalClusters       []*rds.GlobalCluster
+	clusters             []*rds.DBCluster
+	blueGreenDeployments []*rds.BlueGreenDeployment
+}
+
+func (f fakeRDSGlobalClusterClient) DescribeGlobalClustersPagesWithContext(ctx context.Context, in *rds.DescribeGlobalClustersInput, fn func(*rds.DescribeGlobalClustersOutput, bool) bool, opts ...interface{}) error {
+	fn(&rds.DescribeGlobalClustersOutput{GlobalClusters: f.globalClusters}, true)
+	return nil
+}
+
+func (f fakeRDSGlobalClusterClient) DescribeDBClustersPagesWithContext(ctx context.Context, in *rds.DescribeDBClustersInput, fn func(*rds.DescribeDBClustersOutput, bool) bool, opts ...interface{}) error {
+	fn(&rds.DescribeDBClustersOutput{DBClusters: f.clusters}, true)
+	return nil
+}
+
+func (f fakeRDSGlobalClusterClient) DescribeBlueGreenDeploymentsPagesWithContext(ctx context.Context, in *rds.DescribeBlueGreenDeploymentsInput, fn func(*rds.DescribeBlueGreenDeploymentsOutput, bool) bool, opts ...interface{}) error {
+	fn(&rds.DescribeBlueGreenDeploymentsOutput{BlueGreenDeployments: f.blueGreenDeployments}, true)
+	return nil
+}
+
+func TestRDSGlobalClusterScannerScanRegion(t *testing.T) {
+	client := fakeRDSGlobalClusterClient{
+		globalClusters: []*rds.GlobalCluster{
+			{
+				GlobalClusterIdentifier: aws.String("my-global-db"),
+				GlobalClusterMembers: []*rds.GlobalClusterMember{
+					{DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"), IsWriter: aws.Bool(true)},
+					{DBClusterArn: aws.String("arn:aws:rds:us-east-1:123456789012:cluster:secondary"), IsWriter: aws.Bool(false)},
+				},
+			},
+		},
+		clusters: []*rds.DBCluster{
+			{
+				DBClusterArn:        aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+				DBClusterIdentifier: aws.String("primary"),
+				Endpoint:            aws.String("primary.cluster.us-west-2.rds.amazonaws.com"),
+				Port:                aws.Int64(5432),
+			},
+			{
+				DBClusterArn:        aws.String("arn:aws:rds:us-east-1:123456789012:cluster:secondary"),
+				DBClusterIdentifier: aws.String("secondary"),
+				Endpoint:            aws.String("secondary.cluster.us-east-1.rds.amazonaws.com"),
+				Port:                aws.Int64(5432),
+			},
+		},
+	}
+
+	scanner := RDSGlobalClusterScanner{Client: client}
+
+	west, err := scanner.ScanRegion(context.Background(), "us-west-2")
+	require.NoError(t, err)
+	require.Len(t, west, 1)
+	require.Equal(t, "primary", west[0].Name)
+	require.Equal(t, services.RDSGlobalClusterEndpointTypePrimary, west[0].Labels[services.DiscoveryLabelEndpointType])
+
+	east, err := scanner.ScanRegion(context.Background(), "us-east-1")
+	require.NoError(t, err)
+	require.Len(t, east, 1)
+	require.Equal(t, "secondary", east[0].Name)
+	require.Equal(t, services.RDSGlobalClusterEndpointTypeSecondaryWriter, east[0].Labels[services.DiscoveryLabelEndpointType])
+}
+
+func TestRDSGlobalClusterScannerScanRegionBlueGreen(t *testing.T) {
+	client := fakeRDSGlobalClusterClient{
+		globalClusters: []*rds.GlobalCluster{
+			{
+				GlobalClusterIdentifier: aws.String("my-global-db"),
+				GlobalClusterMembers: []*rds.GlobalClusterMember{
+					{DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"), IsWriter: aws.Bool(true)},
+				},
+			},
+		},
+		clusters: []*rds.DBCluster{
+			{
+				DBClusterArn:        aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+				DBClusterIdentifier: aws.String("primary"),
+				Endpoint:            aws.String("primary.cluster.us-west-2.rds.amazonaws.com"),
+				Port:                aws.Int64(5432),
+			},
+		},
+		blueGreenDeployments: []*rds.BlueGreenDeployment{
+			{
+				BlueGreenDeploymentIdentifier: aws.String("bgd-abc123"),
+				Status:                        aws.String("AVAILABLE"),
+				Source:                        aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+				Target:                        aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary-green"),
+			},
+		},
+	}
+
+	scanner := RDSGlobalClusterScanner{Client: client}
+	databases, err := scanner.ScanRegion(context.Background(), "us-west-2")
+	require.NoError(t, err)
+	require.Len(t, databases, 1)
+	require.Equal(t, "primary", databases[0].Name)
+	require.Equal(t, services.RDSBlueGreenRoleSource, databases[0].Labels[services.RDSBlueGreenRoleLabel])
+	require.Equal(t, "bgd-abc123", databases[0].Labels[services.RDSBlueGreenIDLabel])
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// RDSProxyScanner is a [RegionScanner] that discovers RDS Proxies and their
+// custom endpoints, resolving each one's protocol, default port, and TLS
+// server name via services.NewRDSProxyEndpointInfo.
+type RDSProxyScanner struct {
+	// Client lists proxies and their custom endpoints.
+	Client RDSClient
+}
+
+// ScanRegion lists every RDS Proxy in region, plus each proxy's custom
+// endpoints. A proxy whose engine family NewRDSProxyEndpointInfo doesn't
+// recognize is skipped rather than failing the whole region, since RDS
+// Proxy can add engine families before Teleport has mapped them.
+func (s RDSProxyScanner) ScanRegion(ctx context.Context, region string) ([]Database, error) {
+	var proxies []*rds.DBProxy
+	err := s.Client.DescribeDBProxiesPagesWithContext(ctx, &rds.DescribeDBProxiesInput{}, func(page *rds.DescribeDBProxiesOutput, lastPage bool) bool {
+		proxies = append(proxies, page.DBProxies...)
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "describing RDS proxies in %q", region)
+	}
+
+	var endpoints []*rds.DBProxyEndpoint
+	err = s.Client.DescribeDBProxyEndpointsPagesWithContext(ctx, &rds.DescribeDBProxyEndpointsInput{}, func(page *rds.DescribeDBProxyEndpointsOutput, lastPage bool) bool {
+		endpoints = append(endpoints, page.DBProxyEndpoints...)
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "describing RDS proxy endpoints in %q", region)
+	}
+
+	byProxyName := make(map[string]*rds.DBProxy, len(proxies))
+	var databases []Database
+	for _, proxy := range proxies {
+		if proxy == nil || proxy.DBProxyName == nil || proxy.EngineFamily == nil || proxy.Endpoint == nil {
+			continue
+		}
+		byProxyName[*proxy.DBProxyName] = proxy
+
+		info, err := services.NewRDSProxyEndpointInfo(*proxy.EngineFamily, *proxy.Endpoint)
+		if err != nil {
+			continue
+		}
+		databases = append(databases, Database{
+			Name: *proxy.DBProxyName,
+			URI:  formatProxyHostPort(*proxy.Endpoint, info.DefaultPort),
+			Labels: map[string]string{
+				"protocol":        info.Protocol,
+				"tls-server-name": info.TLSServerName,
+			},
+		})
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint == nil || endpoint.DBProxyEndpointName == nil || endpoint.DBProxyName == nil || endpoint.Endpoint == nil {
+			continue
+		}
+		proxy, ok := byProxyName[*endpoint.DBProxyName]
+		if !ok || proxy.EngineFamily == nil || proxy.Endpoint == nil {
+			continue
+		}
+
+		info, err := services.NewRDSProxyEndpointInfo(*proxy.EngineFamily, *proxy.Endpoint)
+		if err != nil {
+			continue
+		}
+		databases = append(databases, Database{
+			Name: *endpoint.DBProxyName + "-" + *endpoint.DBProxyEndpointName,
+			URI:  formatProxyHostPort(*endpoint.Endpoint, info.DefaultPort),
+			Labels: map[string]string{
+				"protocol":        info.Protocol,
+				"tls-server-name": info.TLSServerName,
+			},
+		})
+	}
+
+	return databases, nil
+}
+
+func formatProxyHostPort(host string, port int) string {
+	return fmt.Sprintf("%s:%d", host, port)
+}
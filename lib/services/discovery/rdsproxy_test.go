@@ -0,0 +1,89 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRDSProxyClient struct {
+	RDSClient
+	proxies   []*rds.DBProxy
+	endpoints []*rds.DBProxyEndpoint
+}
+
+func (f fakeRDSProxyClient) DescribeDBProxiesPagesWithContext(ctx context.Context, in *rds.DescribeDBProxiesInput, fn func(*rds.DescribeDBProxiesOutput, bool) bool, opts ...interface{}) error {
+	fn(&rds.DescribeDBProxiesOutput{DBProxies: f.proxies}, true)
+	return nil
+}
+
+func (f fakeRDSProxyClient) DescribeDBProxyEndpointsPagesWithContext(ctx context.Context, in *rds.DescribeDBProxyEndpointsInput, fn func(*rds.DescribeDBProxyEndpointsOutput, bool) bool, opts ...interface{}) error {
+	fn(&rds.DescribeDBProxyEndpointsOutput{DBProxyEndpoints: f.endpoints}, true)
+	return nil
+}
+
+func TestRDSProxyScannerScanRegion(t *testing.T) {
+	client := fakeRDSProxyClient{
+		proxies: []*rds.DBProxy{
+			{
+				DBProxyName:  aws.String("my-proxy"),
+				EngineFamily: aws.String("POSTGRESQL"),
+				Endpoint:     aws.String("my-proxy.proxy-abc.us-west-2.rds.amazonaws.com"),
+			},
+			{
+				DBProxyName:  aws.String("unsupported-proxy"),
+				EngineFamily: aws.String("MARIADB"),
+				Endpoint:     aws.String("unsupported.proxy-abc.us-west-2.rds.amazonaws.com"),
+			},
+		},
+		endpoints: []*rds.DBProxyEndpoint{
+			{
+				DBProxyName:         aws.String("my-proxy"),
+				DBProxyEndpointName: aws.String("read-only"),
+				Endpoint:            aws.String("read-only.my-proxy.proxy-abc.us-west-2.rds.amazonaws.com"),
+			},
+		},
+	}
+
+	scanner := RDSProxyScanner{Client: client}
+	databases, err := scanner.ScanRegion(context.Background(), "us-west-2")
+	require.NoError(t, err)
+	require.Len(t, databases, 2)
+
+	byName := make(map[string]Database, len(databases))
+	for _, db := range databases {
+		byName[db.Name] = db
+	}
+
+	proxy, ok := byName["my-proxy"]
+	require.True(t, ok)
+	require.Equal(t, "my-proxy.proxy-abc.us-west-2.rds.amazonaws.com:5432", proxy.URI)
+	require.Equal(t, "postgres", proxy.Labels["protocol"])
+
+	endpoint, ok := byName["my-proxy-read-only"]
+	require.True(t, ok)
+	require.Equal(t, "read-only.my-proxy.proxy-abc.us-west-2.rds.amazonaws.com:5432", endpoint.URI)
+	require.Equal(t, "my-proxy.proxy-abc.us-west-2.rds.amazonaws.com", endpoint.Labels["tls-server-name"])
+
+	_, skipped := byName["unsupported-proxy"]
+	require.False(t, skipped)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package discovery scans cloud provider APIs for databases and converts
+// them into Teleport database resources. It replaces the sequential,
+// all-or-nothing discovery pass: each enabled region is scanned
+// concurrently, and a failure in one region is recorded in
+// ScanResult.Errors rather than aborting the other regions' results.
+package discovery
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// Database is the result of converting one discovered cloud database
+// instance, cluster, or proxy into Teleport's discovery shape. It mirrors
+// the subset of (planned) types.DatabaseV3 that discovery itself cares
+// about; the full conversion lives in the per-service converters in
+// lib/services (NewDatabaseFromRDSInstance and friends).
+type Database struct {
+	// Name is the discovered resource's Teleport name.
+	Name string
+	// URI is the database's connection endpoint, host:port.
+	URI string
+	// Labels are the discovery labels attached by the converter, e.g.
+	// region, engine, and account ID.
+	Labels map[string]string
+}
+
+// ScanResult is the outcome of scanning one or more regions. Databases
+// collects everything successfully converted; Errors collects one error per
+// region or API call that failed, so a caller can log and proceed with a
+// partial result instead of discarding a whole scan over one bad region.
+type ScanResult struct {
+	Databases []Database
+	Errors    []error
+}
+
+// merge folds other into r, used to combine the per-region results produced
+// by Scanner.Scan's errgroup fan-out.
+func (r *ScanResult) merge(other ScanResult) {
+	r.Databases = append(r.Databases, other.Databases...)
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
+// Scanner discovers databases across a set of regions.
+type Scanner interface {
+	// Scan scans the given regions and returns every database found, along
+	// with any per-region errors encountered.
+	Scan(ctx context.Context, regions []string) (*ScanResult, error)
+}
+
+// RegionScanner discovers databases within a single region. AWSScanner calls
+// one RegionScanner per region, fanned out concurrently.
+type RegionScanner interface {
+	// ScanRegion returns every database found in region.
+	ScanRegion(ctx context.Context, region string) ([]Database, error)
+}
+
+// AWSScannerConfig configures an [AWSScanner].
+type AWSScannerConfig struct {
+	// AssumeRoleARN is the IAM role to scan under. AWSScanner stores it but
+	// does not assume it; the scanners from GetRegionScanner run with
+	// whatever credentials they were built with.
+	AssumeRoleARN string
+	// GetRegionScanner returns the RegionScanner to use for a given region.
+	// Required.
+	GetRegionScanner func(ctx context.Context, region string) (RegionScanner, error)
+	// Concurrency bounds how many regions are scanned at once. Defaults to 5.
+	Concurrency int
+	// CloudIPRangeFeed, if set, is used to resolve each discovered database's
+	// host to a CIDR/service-network pair and label it accordingly. It's
+	// engine-agnostic, so it runs once per database here rather than being
+	// duplicated into every RegionScanner implementation.
+	CloudIPRangeFeed *services.CloudIPRangeFeed
+}
+
+func (c *AWSScannerConfig) checkAndSetDefaults() error {
+	if c.GetRegionScanner == nil {
+		return trace.BadParameter("GetRegionScanner is required")
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 5
+	}
+	return nil
+}
+
+// AWSScanner is a [Scanner] that scans AWS regions concurrently, up to
+// Concurrency at a time. It does not assume AssumeRoleARN itself.
+type AWSScanner struct {
+	cfg AWSScannerConfig
+}
+
+// NewAWSScanner creates an [*AWSScanner].
+func NewAWSScanner(cfg AWSScannerConfig) (*AWSScanner, error) {
+	if err := cfg.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &AWSScanner{cfg: cfg}, nil
+}
+
+// Scan fans out ScanRegion across regions with at most cfg.Concurrency in
+// flight at once. A region whose scanner can't be constructed, or whose
+// ScanRegion call fails, contributes an error to the result instead of
+// failing the whole scan.
+func (s *AWSScanner) Scan(ctx context.Context, regions []string) (*ScanResult, error) {
+	if len(regions) == 0 {
+		return nil, trace.BadParameter("no regions to scan")
+	}
+
+	var mu sync.Mutex
+	result := &ScanResult{}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(s.cfg.Concurrency)
+
+	for _, region := range regions {
+		region := region
+		group.Go(func() error {
+			databases, err := s.scanRegion(groupCtx, region)
+
+			var regionResult ScanResult
+			if err != nil {
+				regionResult.Errors = []error{trace.Wrap(err, "scanning region %q", region)}
+			} else {
+				regionResult.Databases = databases
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.merge(regionResult)
+			return nil
+		})
+	}
+
+	// The per-region goroutines never return an error themselves (failures
+	// go into result.Errors instead), so this only reports a context
+	// cancellation/deadline from the caller.
+	if err := group.Wait(); err != nil {
+		return result, trace.Wrap(err)
+	}
+	return result, nil
+}
+
+func (s *AWSScanner) scanRegion(ctx context.Context, region string) ([]Database, error) {
+	regionScanner, err := s.cfg.GetRegionScanner(ctx, region)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	databases, err := regionScanner.ScanRegion(ctx, region)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if s.cfg.CloudIPRangeFeed != nil {
+		for i, db := range databases {
+			labels, err := services.ApplyCloudIPRangeLabels(s.cfg.CloudIPRangeFeed, db.Labels, hostFromURI(db.URI))
+			if err != nil {
+				return nil, trace.Wrap(err, "applying cloud IP-range labels to %q", db.Name)
+			}
+			databases[i].Labels = labels
+		}
+	}
+
+	return databases, nil
+}
+
+// hostFromURI strips the port off a "host:port" database URI, so it can be
+// matched against a CloudIPRangeFeed's subnet/service-network entries.
+func hostFromURI(uri string) string {
+	host, _, _ := strings.Cut(uri, ":")
+	return host
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+type fakeRegionScanner struct {
+	databases []Database
+	err       error
+}
+
+func (f fakeRegionScanner) ScanRegion(ctx context.Context, region string) ([]Database, error) {
+	return f.databases, f.err
+}
+
+func TestAWSScannerScan(t *testing.T) {
+	scanners := map[string]fakeRegionScanner{
+		"us-west-2": {databases: []Database{{Name: "west-db"}}},
+		"us-east-1": {databases: []Database{{Name: "east-db"}}},
+		"eu-west-1": {err: trace.ConnectionProblem(nil, "describe instances: timeout")},
+	}
+
+	scanner, err := NewAWSScanner(AWSScannerConfig{
+		GetRegionScanner: func(ctx context.Context, region string) (RegionScanner, error) {
+			s, ok := scanners[region]
+			if !ok {
+				return nil, trace.NotFound("no fake scanner for region %q", region)
+			}
+			return s, nil
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), []string{"us-west-2", "us-east-1", "eu-west-1"})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(result.Databases))
+	for _, db := range result.Databases {
+		names = append(names, db.Name)
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{"east-db", "west-db"}, names)
+
+	require.Len(t, result.Errors, 1)
+	require.ErrorContains(t, result.Errors[0], "timeout")
+}
+
+func TestAWSScannerScanNoRegions(t *testing.T) {
+	scanner, err := NewAWSScanner(AWSScannerConfig{
+		GetRegionScanner: func(ctx context.Context, region string) (RegionScanner, error) {
+			return fakeRegionScanner{}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = scanner.Scan(context.Background(), nil)
+	require.True(t, trace.IsBadParameter(err))
+}
+
+func TestAWSScannerScanAppliesCloudIPRangeLabels(t *testing.T) {
+	feed := &services.CloudIPRangeFeed{
+		Fetch: func() ([]services.CloudIPRangeEntry, error) {
+			return []services.CloudIPRangeEntry{
+				{CIDR: "10.20.0.0/16", ServiceNetwork: "us-west-2/rds"},
+			}, nil
+		},
+	}
+
+	scanner, err := NewAWSScanner(AWSScannerConfig{
+		GetRegionScanner: func(ctx context.Context, region string) (RegionScanner, error) {
+			return fakeRegionScanner{databases: []Database{
+				{Name: "west-db", URI: "db.us-west-2.rds.amazonaws.com:5432"},
+			}}, nil
+		},
+		CloudIPRangeFeed: feed,
+	})
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), []string{"us-west-2"})
+	require.NoError(t, err)
+	require.Len(t, result.Databases, 1)
+	require.Equal(t, "10.20.0.0/16", result.Databases[0].Labels[services.DatabaseCIDRLabel])
+	require.Equal(t, "us-west-2/rds", result.Databases[0].Labels[services.DatabaseServiceNetworkLabel])
+}
+
+func TestNewAWSScannerRequiresGetRegionScanner(t *testing.T) {
+	_, err := NewAWSScanner(AWSScannerConfig{})
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/services/common"
+)
+
+// AWSDatabaseNameOverrideLabels lists the tag keys a discovered AWS resource
+// can carry to override the name Teleport would otherwise derive from its
+// cluster/instance identifier. The first matching label wins.
+//
+// This is now an alias for common.DatabaseNameOverrideLabels, which
+// generalizes the same convention to Azure and GCP; new code should refer
+// to that list directly.
+var AWSDatabaseNameOverrideLabels = common.DatabaseNameOverrideLabels
+
+// DocumentDB is the (planned) types.AWS.DocumentDB sub-struct.
+type DocumentDB struct {
+	// ClusterID is the cluster's DBClusterIdentifier.
+	ClusterID string
+	// EndpointType is DiscoveryLabelEndpointType's value: "cluster",
+	// "reader", or "instance".
+	EndpointType string
+	// InstanceID is set when EndpointType is "instance".
+	InstanceID string
+}
+
+// Neptune is the (planned) types.AWS.Neptune sub-struct, structurally
+// identical to DocumentDB.
+type Neptune struct {
+	// ClusterID is the cluster's DBClusterIdentifier.
+	ClusterID string
+	// EndpointType is DiscoveryLabelEndpointType's value: "cluster" or
+	// "reader".
+	EndpointType string
+	// InstanceID is left empty by the Neptune converters, which only
+	// produce cluster and reader endpoints.
+	InstanceID string
+}
+
+// DocumentDBDatabase is a database discovered from a DocumentDB cluster's
+// primary or reader endpoint.
+type DocumentDBDatabase struct {
+	// Name is the discovered resource's Teleport name, subject to
+	// AWSDatabaseNameOverrideLabels.
+	Name string
+	// Protocol is always "mongodb": DocumentDB speaks the MongoDB wire
+	// protocol.
+	Protocol string
+	// URI is the endpoint's host:port.
+	URI string
+	// Labels carries DiscoveryLabelEndpointType plus any tags passed in.
+	Labels map[string]string
+	// DocumentDB identifies the cluster/instance for IAM auth and routing.
+	DocumentDB DocumentDB
+}
+
+// NeptuneDatabase is a database discovered from a Neptune cluster's primary
+// or reader endpoint.
+type NeptuneDatabase struct {
+	// Name is the discovered resource's Teleport name, subject to
+	// AWSDatabaseNameOverrideLabels.
+	Name string
+	// Protocol is always "neptune".
+	Protocol string
+	// URI is the endpoint's host:port.
+	URI string
+	// Labels carries DiscoveryLabelEndpointType plus any tags passed in.
+	Labels map[string]string
+	// Neptune identifies the cluster/instance for IAM auth and routing.
+	Neptune Neptune
+}
+
+// NewDatabaseFromDocumentDBCluster converts a DocumentDB cluster's primary
+// endpoint into a DocumentDBDatabase, applying any
+// AWSDatabaseNameOverrideLabels tag.
+func NewDatabaseFromDocumentDBCluster(cluster *docdb.DBCluster, extraLabels map[string]string) (*DocumentDBDatabase, error) {
+	if cluster == nil || cluster.DBClusterIdentifier == nil || cluster.Endpoint == nil {
+		return nil, trace.BadParameter("cluster is missing an identifier or endpoint")
+	}
+
+	return &DocumentDBDatabase{
+		Name:     nameWithOverride(*cluster.DBClusterIdentifier, extraLabels),
+		Protocol: "mongodb",
+		URI:      formatHostPort(*cluster.Endpoint, clusterPortInt64(cluster.Port)),
+		Labels:   mergeDatabaseLabels(extraLabels, map[string]string{DiscoveryLabelEndpointType: "cluster"}),
+		DocumentDB: DocumentDB{
+			ClusterID:    *cluster.DBClusterIdentifier,
+			EndpointType: "cluster",
+		},
+	}, nil
+}
+
+// NewDatabaseFromDocumentDBClusterReaderEndpoint converts a DocumentDB
+// cluster's reader endpoint into a DocumentDBDatabase.
+func NewDatabaseFromDocumentDBClusterReaderEndpoint(cluster *docdb.DBCluster, extraLabels map[string]string) (*DocumentDBDatabase, error) {
+	if cluster == nil || cluster.DBClusterIdentifier == nil || cluster.ReaderEndpoint == nil {
+		return nil, trace.BadParameter("cluster is missing an identifier or reader endpoint")
+	}
+
+	return &DocumentDBDatabase{
+		Name:     nameWithOverride(*cluster.DBClusterIdentifier+"-reader", extraLabels),
+		Protocol: "mongodb",
+		URI:      formatHostPort(*cluster.ReaderEndpoint, clusterPortInt64(cluster.Port)),
+		Labels:   mergeDatabaseLabels(extraLabels, map[string]string{DiscoveryLabelEndpointType: "reader"}),
+		DocumentDB: DocumentDB{
+			ClusterID:    *cluster.DBClusterIdentifier,
+			EndpointType: "reader",
+		},
+	}, nil
+}
+
+// NewDatabaseFromNeptuneCluster converts a Neptune cluster's primary
+// endpoint into a NeptuneDatabase.
+func NewDatabaseFromNeptuneCluster(cluster *neptune.DBCluster, extraLabels map[string]string) (*NeptuneDatabase, error) {
+	if cluster == nil || cluster.DBClusterIdentifier == nil || cluster.Endpoint == nil {
+		return nil, trace.BadParameter("cluster is missing an identifier or endpoint")
+	}
+
+	return &NeptuneDatabase{
+		Name:     nameWithOverride(*cluster.DBClusterIdentifier, extraLabels),
+		Protocol: "neptune",
+		URI:      formatHostPort(*cluster.Endpoint, clusterPortInt64(cluster.Port)),
+		Labels:   mergeDatabaseLabels(extraLabels, map[string]string{DiscoveryLabelEndpointType: "cluster"}),
+		Neptune: Neptune{
+			ClusterID:    *cluster.DBClusterIdentifier,
+			EndpointType: "cluster",
+		},
+	}, nil
+}
+
+// NewDatabaseFromNeptuneClusterReaderEndpoint converts a Neptune cluster's
+// reader endpoint into a NeptuneDatabase.
+func NewDatabaseFromNeptuneClusterReaderEndpoint(cluster *neptune.DBCluster, extraLabels map[string]string) (*NeptuneDatabase, error) {
+	if cluster == nil || cluster.DBClusterIdentifier == nil || cluster.ReaderEndpoint == nil {
+		return nil, trace.BadParameter("cluster is missing an identifier or reader endpoint")
+	}
+
+	return &NeptuneDatabase{
+		Name:     nameWithOverride(*cluster.DBClusterIdentifier+"-reader", extraLabels),
+		Protocol: "neptune",
+		URI:      formatHostPort(*cluster.ReaderEndpoint, clusterPortInt64(cluster.Port)),
+		Labels:   mergeDatabaseLabels(extraLabels, map[string]string{DiscoveryLabelEndpointType: "reader"}),
+		Neptune: Neptune{
+			ClusterID:    *cluster.DBClusterIdentifier,
+			EndpointType: "reader",
+		},
+	}, nil
+}
+
+// NeptuneClusterSupportsIAMAuth reports whether a Neptune cluster has IAM
+// database authentication enabled, so the discovery poller can tell agents
+// to generate SigV4 auth tokens instead of falling back to cluster-local
+// credentials.
+func NeptuneClusterSupportsIAMAuth(cluster *neptune.DBCluster) bool {
+	return cluster != nil && aws.BoolValue(cluster.IAMDatabaseAuthenticationEnabled)
+}
+
+func nameWithOverride(defaultName string, labels map[string]string) string {
+	for _, overrideLabel := range AWSDatabaseNameOverrideLabels {
+		if name, ok := labels[overrideLabel]; ok && name != "" {
+			return name
+		}
+	}
+	return defaultName
+}
+
+func mergeDatabaseLabels(extraLabels, generated map[string]string) map[string]string {
+	labels := make(map[string]string, len(extraLabels)+len(generated))
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	for k, v := range generated {
+		labels[k] = v
+	}
+	return labels
+}
+
+func clusterPortInt64(port *int64) int64 {
+	if port == nil {
+		return 0
+	}
+	return *port
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseFromDocumentDBCluster(t *testing.T) {
+	cluster := &docdb.DBCluster{
+		DBClusterIdentifier: aws.String("my-docdb-cluster"),
+		Endpoint:            aws.String("my-docdb-cluster.cluster-xxx.us-east-1.docdb.amazonaws.com"),
+		ReaderEndpoint:      aws.String("my-docdb-cluster.cluster-ro-xxx.us-east-1.docdb.amazonaws.com"),
+		Port:                aws.Int64(27017),
+	}
+
+	db, err := NewDatabaseFromDocumentDBCluster(cluster, map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, "my-docdb-cluster", db.Name)
+	require.Equal(t, "mongodb", db.Protocol)
+	require.Equal(t, "my-docdb-cluster.cluster-xxx.us-east-1.docdb.amazonaws.com:27017", db.URI)
+	require.Equal(t, "cluster", db.Labels[DiscoveryLabelEndpointType])
+	require.Equal(t, "prod", db.Labels["env"])
+	require.Equal(t, "my-docdb-cluster", db.DocumentDB.ClusterID)
+
+	reader, err := NewDatabaseFromDocumentDBClusterReaderEndpoint(cluster, nil)
+	require.NoError(t, err)
+	require.Equal(t, "my-docdb-cluster-reader", reader.Name)
+	require.Equal(t, "reader", reader.Labels[DiscoveryLabelEndpointType])
+}
+
+func TestNewDatabaseFromDocumentDBClusterNameOverride(t *testing.T) {
+	cluster := &docdb.DBCluster{
+		DBClusterIdentifier: aws.String("my-docdb-cluster"),
+		Endpoint:            aws.String("endpoint.docdb.amazonaws.com"),
+	}
+
+	for _, overrideLabel := range AWSDatabaseNameOverrideLabels {
+		t.Run("via "+overrideLabel, func(t *testing.T) {
+			db, err := NewDatabaseFromDocumentDBCluster(cluster, map[string]string{overrideLabel: "custom-name"})
+			require.NoError(t, err)
+			require.Equal(t, "custom-name", db.Name)
+		})
+	}
+}
+
+func TestNewDatabaseFromNeptuneCluster(t *testing.T) {
+	cluster := &neptune.DBCluster{
+		DBClusterIdentifier:              aws.String("my-neptune-cluster"),
+		Endpoint:                         aws.String("my-neptune-cluster.cluster-xxx.us-east-1.neptune.amazonaws.com"),
+		ReaderEndpoint:                   aws.String("my-neptune-cluster.cluster-ro-xxx.us-east-1.neptune.amazonaws.com"),
+		Port:                             aws.Int64(8182),
+		IAMDatabaseAuthenticationEnabled: aws.Bool(true),
+	}
+
+	db, err := NewDatabaseFromNeptuneCluster(cluster, nil)
+	require.NoError(t, err)
+	require.Equal(t, "my-neptune-cluster", db.Name)
+	require.Equal(t, "neptune", db.Protocol)
+	require.Equal(t, "my-neptune-cluster.cluster-xxx.us-east-1.neptune.amazonaws.com:8182", db.URI)
+	require.True(t, NeptuneClusterSupportsIAMAuth(cluster))
+
+	reader, err := NewDatabaseFromNeptuneClusterReaderEndpoint(cluster, nil)
+	require.NoError(t, err)
+	require.Equal(t, "my-neptune-cluster-reader", reader.Name)
+}
+
+func TestNeptuneClusterSupportsIAMAuthFalse(t *testing.T) {
+	require.False(t, NeptuneClusterSupportsIAMAuth(nil))
+	require.False(t, NeptuneClusterSupportsIAMAuth(&neptune.DBCluster{}))
+}
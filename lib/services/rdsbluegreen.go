@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+const (
+	// RDSBlueGreenIDLabel names the blue/green deployment an instance or
+	// cluster belongs to, so role-based access policies can target either
+	// side of a switchover explicitly.
+	RDSBlueGreenIDLabel = "teleport.dev/rds-bluegreen-id"
+	// RDSBlueGreenRoleLabel is RDSBlueGreenRoleSource or RDSBlueGreenRoleTarget.
+	RDSBlueGreenRoleLabel = "teleport.dev/rds-bluegreen-role"
+	// RDSBlueGreenStatusLabel mirrors the deployment's Status field from
+	// DescribeBlueGreenDeployments, e.g. "AVAILABLE" or "SWITCHOVER_IN_PROGRESS".
+	RDSBlueGreenStatusLabel = "teleport.dev/rds-bluegreen-status"
+)
+
+// RDS Blue/Green Deployment roles. The source is the original, currently
+// live instance or cluster; the target is the newly provisioned green
+// environment that becomes live after switchover.
+const (
+	RDSBlueGreenRoleSource = "source"
+	RDSBlueGreenRoleTarget = "target"
+)
+
+// RDSBlueGreenDeployment is the subset of a DescribeBlueGreenDeployments
+// result WithRDSBlueGreenLabels needs to label a converted database.
+type RDSBlueGreenDeployment struct {
+	// ID is the deployment's BlueGreenDeploymentIdentifier.
+	ID string
+	// Status is the deployment's current Status, e.g. "AVAILABLE".
+	Status string
+	// Role is RDSBlueGreenRoleSource or RDSBlueGreenRoleTarget, identifying
+	// which side of the deployment the instance/cluster being converted is.
+	Role string
+}
+
+// WithRDSBlueGreenLabels returns name and labels updated for a blue/green
+// deployment: the target side gets a "-green" name suffix so it doesn't
+// collide with the source's name in the catalog, and both sides get
+// RDSBlueGreenIDLabel/RDSBlueGreenRoleLabel/RDSBlueGreenStatusLabel. When bg
+// is the zero value (the instance/cluster isn't part of a blue/green
+// deployment), name and labels are returned unchanged.
+func WithRDSBlueGreenLabels(name string, labels map[string]string, bg RDSBlueGreenDeployment) (string, map[string]string) {
+	if bg.ID == "" {
+		return name, labels
+	}
+
+	out := make(map[string]string, len(labels)+3)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[RDSBlueGreenIDLabel] = bg.ID
+	out[RDSBlueGreenRoleLabel] = bg.Role
+	if bg.Status != "" {
+		out[RDSBlueGreenStatusLabel] = bg.Status
+	}
+
+	if bg.Role == RDSBlueGreenRoleTarget {
+		name += "-green"
+	}
+	return name, out
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRDSBlueGreenLabels(t *testing.T) {
+	t.Run("not part of a deployment", func(t *testing.T) {
+		name, labels := WithRDSBlueGreenLabels("mydb", map[string]string{"env": "prod"}, RDSBlueGreenDeployment{})
+		require.Equal(t, "mydb", name)
+		require.Equal(t, map[string]string{"env": "prod"}, labels)
+	})
+
+	t.Run("source side keeps its name", func(t *testing.T) {
+		name, labels := WithRDSBlueGreenLabels("mydb", nil, RDSBlueGreenDeployment{
+			ID:     "bgd-abc123",
+			Status: "AVAILABLE",
+			Role:   RDSBlueGreenRoleSource,
+		})
+		require.Equal(t, "mydb", name)
+		require.Equal(t, "bgd-abc123", labels[RDSBlueGreenIDLabel])
+		require.Equal(t, RDSBlueGreenRoleSource, labels[RDSBlueGreenRoleLabel])
+		require.Equal(t, "AVAILABLE", labels[RDSBlueGreenStatusLabel])
+	})
+
+	t.Run("target side gets a -green suffix", func(t *testing.T) {
+		name, labels := WithRDSBlueGreenLabels("mydb", nil, RDSBlueGreenDeployment{
+			ID:   "bgd-abc123",
+			Role: RDSBlueGreenRoleTarget,
+		})
+		require.Equal(t, "mydb-green", name)
+		require.Equal(t, RDSBlueGreenRoleTarget, labels[RDSBlueGreenRoleLabel])
+	})
+}
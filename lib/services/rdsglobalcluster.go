@@ -0,0 +1,152 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/gravitational/trace"
+)
+
+// RDS Global Database endpoint types. A global cluster has exactly one
+// "primary" endpoint, in the region that currently accepts writes; every
+// other region contributes a "secondary-writer" endpoint (used for local
+// write-forwarding) and, if the member cluster has readers, a "reader"
+// endpoint.
+const (
+	RDSGlobalClusterEndpointTypePrimary         = "primary"
+	RDSGlobalClusterEndpointTypeReader          = "reader"
+	RDSGlobalClusterEndpointTypeSecondaryWriter = "secondary-writer"
+
+	// DiscoveryLabelGlobalClusterID and DiscoveryLabelRegion are attached to
+	// every database NewDatabasesFromRDSGlobalCluster produces.
+	DiscoveryLabelGlobalClusterID = "discovery/global-cluster-id"
+	DiscoveryLabelRegion          = "discovery/region"
+	// DiscoveryLabelEndpointType is set to one of the RDSGlobalClusterEndpointType*
+	// values above.
+	DiscoveryLabelEndpointType = "endpoint-type"
+)
+
+// RDSGlobalClusterDatabase is one endpoint discovered from an Aurora Global
+// Database's topology: the primary region's writer, a secondary region's
+// local writer (for write-forwarding), or a secondary region's reader.
+type RDSGlobalClusterDatabase struct {
+	// Name is the discovered resource's Teleport name.
+	Name string
+	// URI is the endpoint's host:port.
+	URI string
+	// Labels carries DiscoveryLabelGlobalClusterID, DiscoveryLabelRegion, and
+	// DiscoveryLabelEndpointType; the member cluster's tags are not copied.
+	Labels map[string]string
+}
+
+// NewDatabasesFromRDSGlobalCluster walks an Aurora Global Database's
+// topology and returns up to two databases per member region: its writer
+// endpoint (typed primary in the current writer region, secondary-writer
+// elsewhere) and, if present, its reader endpoint, in the primary region as
+// well as the secondary ones. members must contain the *rds.DBCluster
+// referenced by each of gc's GlobalClusterMembers; a member with no
+// matching DBCluster in members is skipped rather than failing the whole
+// conversion, since DescribeGlobalClusters and DescribeDBClusters are
+// queried independently and can race during topology changes.
+func NewDatabasesFromRDSGlobalCluster(gc *rds.GlobalCluster, members []*rds.DBCluster) ([]RDSGlobalClusterDatabase, error) {
+	if gc == nil || gc.GlobalClusterIdentifier == nil {
+		return nil, trace.BadParameter("global cluster is missing an identifier")
+	}
+
+	byARN := make(map[string]*rds.DBCluster, len(members))
+	for _, m := range members {
+		if m != nil && m.DBClusterArn != nil {
+			byARN[*m.DBClusterArn] = m
+		}
+	}
+
+	globalClusterID := *gc.GlobalClusterIdentifier
+
+	var databases []RDSGlobalClusterDatabase
+	for _, gm := range gc.GlobalClusterMembers {
+		if gm == nil || gm.DBClusterArn == nil {
+			continue
+		}
+		cluster, ok := byARN[*gm.DBClusterArn]
+		if !ok {
+			continue
+		}
+
+		region, err := rdsClusterARNRegion(*gm.DBClusterArn)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		isWriter := gm.IsWriter != nil && *gm.IsWriter
+		writerEndpointType := RDSGlobalClusterEndpointTypeSecondaryWriter
+		if isWriter {
+			writerEndpointType = RDSGlobalClusterEndpointTypePrimary
+		}
+
+		if cluster.Endpoint != nil {
+			databases = append(databases, newRDSGlobalClusterDatabase(globalClusterID, region, writerEndpointType, *cluster.DBClusterIdentifier, *cluster.Endpoint, clusterPort(cluster)))
+		}
+		if cluster.ReaderEndpoint != nil {
+			databases = append(databases, newRDSGlobalClusterDatabase(globalClusterID, region, RDSGlobalClusterEndpointTypeReader, *cluster.DBClusterIdentifier, *cluster.ReaderEndpoint, clusterPort(cluster)))
+		}
+	}
+
+	return databases, nil
+}
+
+func newRDSGlobalClusterDatabase(globalClusterID, region, endpointType, clusterID, host string, port int64) RDSGlobalClusterDatabase {
+	name := clusterID
+	if endpointType == RDSGlobalClusterEndpointTypeReader {
+		name += "-reader"
+	}
+	return RDSGlobalClusterDatabase{
+		Name: name,
+		URI:  formatHostPort(host, port),
+		Labels: map[string]string{
+			DiscoveryLabelGlobalClusterID: globalClusterID,
+			DiscoveryLabelRegion:          region,
+			DiscoveryLabelEndpointType:    endpointType,
+		},
+	}
+}
+
+func clusterPort(cluster *rds.DBCluster) int64 {
+	if cluster.Port == nil {
+		return 0
+	}
+	return *cluster.Port
+}
+
+func formatHostPort(host string, port int64) string {
+	if port == 0 {
+		return host
+	}
+	return host + ":" + strconv.FormatInt(port, 10)
+}
+
+// rdsClusterARNRegion extracts the region component of an RDS cluster ARN,
+// e.g. "arn:aws:rds:us-west-2:123456789012:cluster:example" -> "us-west-2".
+func rdsClusterARNRegion(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 4 || parts[3] == "" {
+		return "", trace.BadParameter("malformed RDS cluster ARN %q", arn)
+	}
+	return parts[3], nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabasesFromRDSGlobalCluster(t *testing.T) {
+	gc := &rds.GlobalCluster{
+		GlobalClusterIdentifier: aws.String("my-global-db"),
+		GlobalClusterMembers: []*rds.GlobalClusterMember{
+			{
+				DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+				IsWriter:     aws.Bool(true),
+			},
+			{
+				DBClusterArn: aws.String("arn:aws:rds:us-east-1:123456789012:cluster:secondary"),
+				IsWriter:     aws.Bool(false),
+			},
+		},
+	}
+
+	members := []*rds.DBCluster{
+		{
+			DBClusterArn:       aws.String("arn:aws:rds:us-west-2:123456789012:cluster:primary"),
+			DBClusterIdentifier: aws.String("primary"),
+			Endpoint:           aws.String("primary.cluster.us-west-2.rds.amazonaws.com"),
+			ReaderEndpoint:     aws.String("primary.cluster-ro.us-west-2.rds.amazonaws.com"),
+			Port:               aws.Int64(5432),
+		},
+		{
+			DBClusterArn:       aws.String("arn:aws:rds:us-east-1:123456789012:cluster:secondary"),
+			DBClusterIdentifier: aws.String("secondary"),
+			Endpoint:           aws.String("secondary.cluster.us-east-1.rds.amazonaws.com"),
+			Port:               aws.Int64(5432),
+		},
+	}
+
+	databases, err := NewDatabasesFromRDSGlobalCluster(gc, members)
+	require.NoError(t, err)
+	require.Len(t, databases, 3)
+
+	byName := make(map[string]RDSGlobalClusterDatabase, len(databases))
+	for _, db := range databases {
+		byName[db.Name] = db
+	}
+
+	primary, ok := byName["primary"]
+	require.True(t, ok)
+	require.Equal(t, "primary.cluster.us-west-2.rds.amazonaws.com:5432", primary.URI)
+	require.Equal(t, RDSGlobalClusterEndpointTypePrimary, primary.Labels[DiscoveryLabelEndpointType])
+	require.Equal(t, "us-west-2", primary.Labels[DiscoveryLabelRegion])
+	require.Equal(t, "my-global-db", primary.Labels[DiscoveryLabelGlobalClusterID])
+
+	primaryReader, ok := byName["primary-reader"]
+	require.True(t, ok)
+	require.Equal(t, RDSGlobalClusterEndpointTypeReader, primaryReader.Labels[DiscoveryLabelEndpointType])
+
+	secondary, ok := byName["secondary"]
+	require.True(t, ok)
+	require.Equal(t, RDSGlobalClusterEndpointTypeSecondaryWriter, secondary.Labels[DiscoveryLabelEndpointType])
+	require.Equal(t, "us-east-1", secondary.Labels[DiscoveryLabelRegion])
+}
+
+func TestNewDatabasesFromRDSGlobalClusterMissingIdentifier(t *testing.T) {
+	_, err := NewDatabasesFromRDSGlobalCluster(&rds.GlobalCluster{}, nil)
+	require.Error(t, err)
+}
+
+func TestNewDatabasesFromRDSGlobalClusterSkipsUnmatchedMember(t *testing.T) {
+	gc := &rds.GlobalCluster{
+		GlobalClusterIdentifier: aws.String("my-global-db"),
+		GlobalClusterMembers: []*rds.GlobalClusterMember{
+			{DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:missing")},
+		},
+	}
+
+	databases, err := NewDatabasesFromRDSGlobalCluster(gc, nil)
+	require.NoError(t, err)
+	require.Empty(t, databases)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/gravitational/trace"
+)
+
+// RDS Proxy engine family values, matching rds.EngineFamily* in the AWS SDK.
+// Kept as local string constants rather than importing the SDK package so
+// this mapping can be reused by callers that only have the raw
+// DBProxy.EngineFamily string (and equivalents from the SDK v2 /
+// CloudFormation-style APIs) on hand.
+const (
+	RDSProxyEngineFamilyMySQL      = "MYSQL"
+	RDSProxyEngineFamilyPostgreSQL = "POSTGRESQL"
+	RDSProxyEngineFamilySQLServer  = "SQLSERVER"
+)
+
+// rdsProxyEngineFamilyProtocol maps an RDS Proxy EngineFamily to the
+// Teleport database protocol and the default port to use when the proxy's
+// own endpoint doesn't specify a port.
+var rdsProxyEngineFamilyProtocol = map[string]struct {
+	protocol    string
+	defaultPort int
+}{
+	RDSProxyEngineFamilyMySQL:      {protocol: "mysql", defaultPort: 3306},
+	RDSProxyEngineFamilyPostgreSQL: {protocol: "postgres", defaultPort: 5432},
+	RDSProxyEngineFamilySQLServer:  {protocol: "sqlserver", defaultPort: 1433},
+}
+
+// RDSProxyEngineFamilyToProtocol returns the Teleport database protocol and
+// default port for an RDS Proxy EngineFamily value. It returns
+// trace.BadParameter for engine families RDS Proxy doesn't support, so
+// callers building a database from discovery data can surface an actionable
+// error instead of silently defaulting to MySQL.
+func RDSProxyEngineFamilyToProtocol(engineFamily string) (protocol string, defaultPort int, err error) {
+	mapped, ok := rdsProxyEngineFamilyProtocol[engineFamily]
+	if !ok {
+		return "", 0, trace.BadParameter("unsupported RDS Proxy engine family %q", engineFamily)
+	}
+	return mapped.protocol, mapped.defaultPort, nil
+}
+
+// RDSProxyEndpointInfo bundles the protocol, default port, and TLS server
+// name a database converted from an RDS Proxy (or one of its custom
+// endpoints) should use, returned by NewRDSProxyEndpointInfo.
+type RDSProxyEndpointInfo struct {
+	// Protocol is the Teleport database protocol for the proxy's engine
+	// family.
+	Protocol string
+	// DefaultPort is used when the proxy's own endpoint doesn't specify a
+	// port.
+	DefaultPort int
+	// TLSServerName is the server name to validate the endpoint's
+	// certificate against.
+	TLSServerName string
+}
+
+// NewRDSProxyEndpointInfo resolves engineFamily to its protocol and default
+// port via RDSProxyEngineFamilyToProtocol and pairs it with the TLS server
+// name computed via RDSProxyEndpointTLSServerName from defaultEndpointHost,
+// the proxy's default endpoint host. The same result applies to the default
+// endpoint and to every custom endpoint of that proxy.
+func NewRDSProxyEndpointInfo(engineFamily, defaultEndpointHost string) (RDSProxyEndpointInfo, error) {
+	protocol, defaultPort, err := RDSProxyEngineFamilyToProtocol(engineFamily)
+	if err != nil {
+		return RDSProxyEndpointInfo{}, trace.Wrap(err)
+	}
+	return RDSProxyEndpointInfo{
+		Protocol:      protocol,
+		DefaultPort:   defaultPort,
+		TLSServerName: RDSProxyEndpointTLSServerName(defaultEndpointHost),
+	}, nil
+}
+
+// RDSProxyEndpointTLSServerName returns the TLS server name to set for a
+// database converted from an RDS Proxy custom endpoint.
+// Custom endpoints terminate TLS using the default proxy endpoint's
+// certificate, so the server name must be the default endpoint's host
+// rather than the custom endpoint's own host.
+func RDSProxyEndpointTLSServerName(defaultEndpointHost string) string {
+	return defaultEndpointHost
+}
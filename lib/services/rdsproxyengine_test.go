@@ -0,0 +1,82 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRDSProxyEngineFamilyToProtocol(t *testing.T) {
+	tests := []struct {
+		engineFamily string
+		wantProtocol string
+		wantPort     int
+		wantErr      string
+	}{
+		{
+			engineFamily: RDSProxyEngineFamilyMySQL,
+			wantProtocol: "mysql",
+			wantPort:     3306,
+		},
+		{
+			engineFamily: RDSProxyEngineFamilyPostgreSQL,
+			wantProtocol: "postgres",
+			wantPort:     5432,
+		},
+		{
+			engineFamily: RDSProxyEngineFamilySQLServer,
+			wantProtocol: "sqlserver",
+			wantPort:     1433,
+		},
+		{
+			engineFamily: "MARIADB",
+			wantErr:      "unsupported RDS Proxy engine family",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.engineFamily, func(t *testing.T) {
+			protocol, port, err := RDSProxyEngineFamilyToProtocol(test.engineFamily)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.wantProtocol, protocol)
+			require.Equal(t, test.wantPort, port)
+		})
+	}
+}
+
+func TestRDSProxyEndpointTLSServerName(t *testing.T) {
+	require.Equal(t, "proxy.rds.test", RDSProxyEndpointTLSServerName("proxy.rds.test"))
+}
+
+func TestNewRDSProxyEndpointInfo(t *testing.T) {
+	info, err := NewRDSProxyEndpointInfo(RDSProxyEngineFamilyPostgreSQL, "proxy.rds.test")
+	require.NoError(t, err)
+	require.Equal(t, RDSProxyEndpointInfo{
+		Protocol:      "postgres",
+		DefaultPort:   5432,
+		TLSServerName: "proxy.rds.test",
+	}, info)
+
+	_, err = NewRDSProxyEndpointInfo("MARIADB", "proxy.rds.test")
+	require.ErrorContains(t, err, "unsupported RDS Proxy engine family")
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+// RedshiftAuthAPI identifies which IAM-auth API a Redshift connection
+// should call to obtain temporary database credentials. Provisioned
+// clusters and Redshift Serverless workgroups use different APIs, so the
+// db/common signer must branch on this rather than always calling the
+// provisioned one.
+type RedshiftAuthAPI string
+
+const (
+	// RedshiftAuthAPIGetClusterCredentials calls
+	// redshift:GetClusterCredentials, for provisioned Redshift clusters.
+	RedshiftAuthAPIGetClusterCredentials RedshiftAuthAPI = "redshift:GetClusterCredentials"
+	// RedshiftAuthAPIGetCredentials calls
+	// redshift-serverless:GetCredentials, for Redshift Serverless
+	// workgroups and their VPC endpoints.
+	RedshiftAuthAPIGetCredentials RedshiftAuthAPI = "redshift-serverless:GetCredentials"
+)
+
+// RedshiftAuthAPIForDatabase returns the IAM-auth API the db/common signer
+// should call for db. Any database carrying RedshiftServerless identity
+// (a workgroup name) is Redshift Serverless; everything else is assumed to
+// be a provisioned cluster.
+func RedshiftAuthAPIForDatabase(rs RedshiftServerless) RedshiftAuthAPI {
+	if rs.WorkgroupName != "" {
+		return RedshiftAuthAPIGetCredentials
+	}
+	return RedshiftAuthAPIGetClusterCredentials
+}
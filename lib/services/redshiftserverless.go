@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/gravitational/trace"
+)
+
+const (
+	// DiscoveryLabelNamespaceName is attached to every database converted
+	// from Redshift Serverless and names the namespace the workgroup (or
+	// VPC endpoint) belongs to.
+	DiscoveryLabelNamespaceName = "namespace-name"
+	// DiscoveryLabelWorkgroupName is attached to every database converted
+	// from Redshift Serverless and names the workgroup itself. For a VPC
+	// endpoint this is the workgroup it was created against, not the
+	// endpoint's own name.
+	DiscoveryLabelWorkgroupName = "workgroup-name"
+)
+
+// RedshiftServerless is the (planned) types.AWS.RedshiftServerless
+// sub-struct: the subset of a workgroup or VPC endpoint's identity that a
+// database resource needs to look up IAM-auth credentials at connection
+// time.
+type RedshiftServerless struct {
+	// WorkgroupName is the workgroup's name.
+	WorkgroupName string
+	// WorkgroupID is the workgroup's immutable ID, used to disambiguate a
+	// recreated workgroup that reused its name.
+	WorkgroupID string
+	// EndpointName is set when the database was converted from a VPC
+	// endpoint rather than the workgroup's own default endpoint.
+	EndpointName string
+}
+
+// RedshiftServerlessDatabase is a database discovered from Redshift
+// Serverless: either a workgroup's default endpoint or one of its VPC
+// endpoints.
+type RedshiftServerlessDatabase struct {
+	// Name is the discovered resource's Teleport name.
+	Name string
+	// URI is the endpoint's host:port.
+	URI string
+	// Labels carries DiscoveryLabelNamespaceName, DiscoveryLabelWorkgroupName,
+	// and any tags passed in.
+	Labels map[string]string
+	// RedshiftServerless identifies the workgroup/endpoint for IAM auth.
+	RedshiftServerless RedshiftServerless
+	// AuthAPI is the IAM-auth API the db/common signer should call to
+	// obtain credentials for this database.
+	AuthAPI RedshiftAuthAPI
+}
+
+// NewDatabaseFromRedshiftServerlessWorkgroup converts a Redshift Serverless
+// workgroup's default endpoint into a database.
+func NewDatabaseFromRedshiftServerlessWorkgroup(wg *redshiftserverless.Workgroup, ns *redshiftserverless.Namespace, tags map[string]string) (*RedshiftServerlessDatabase, error) {
+	if wg == nil || wg.WorkgroupName == nil || wg.Endpoint == nil || wg.Endpoint.Address == nil {
+		return nil, trace.BadParameter("workgroup is missing a name or endpoint")
+	}
+
+	namespaceName := ""
+	if ns != nil && ns.NamespaceName != nil {
+		namespaceName = *ns.NamespaceName
+	} else if wg.NamespaceName != nil {
+		namespaceName = *wg.NamespaceName
+	}
+
+	labels := redshiftServerlessLabels(namespaceName, *wg.WorkgroupName, tags)
+	rs := RedshiftServerless{
+		WorkgroupName: *wg.WorkgroupName,
+		WorkgroupID:   aws.StringValue(wg.WorkgroupId),
+	}
+
+	return &RedshiftServerlessDatabase{
+		Name:               *wg.WorkgroupName,
+		URI:                formatHostPort(*wg.Endpoint.Address, endpointPort(wg.Endpoint.Port)),
+		Labels:             labels,
+		RedshiftServerless: rs,
+		AuthAPI:            RedshiftAuthAPIForDatabase(rs),
+	}, nil
+}
+
+// NewDatabaseFromRedshiftServerlessVPCEndpoint converts one of a workgroup's
+// VPC endpoints into a database. The name combines the workgroup and
+// endpoint names since a workgroup can have several VPC endpoints.
+func NewDatabaseFromRedshiftServerlessVPCEndpoint(endpoint *redshiftserverless.EndpointAccess, wg *redshiftserverless.Workgroup, ns *redshiftserverless.Namespace, tags map[string]string) (*RedshiftServerlessDatabase, error) {
+	if endpoint == nil || endpoint.EndpointName == nil || endpoint.Address == nil {
+		return nil, trace.BadParameter("VPC endpoint is missing a name or address")
+	}
+	if wg == nil || wg.WorkgroupName == nil {
+		return nil, trace.BadParameter("VPC endpoint requires its parent workgroup")
+	}
+
+	namespaceName := ""
+	if ns != nil && ns.NamespaceName != nil {
+		namespaceName = *ns.NamespaceName
+	} else if wg.NamespaceName != nil {
+		namespaceName = *wg.NamespaceName
+	}
+
+	labels := redshiftServerlessLabels(namespaceName, *wg.WorkgroupName, tags)
+	labels[DiscoveryLabelWorkgroupName] = *wg.WorkgroupName
+	rs := RedshiftServerless{
+		WorkgroupName: *wg.WorkgroupName,
+		WorkgroupID:   aws.StringValue(wg.WorkgroupId),
+		EndpointName:  *endpoint.EndpointName,
+	}
+
+	return &RedshiftServerlessDatabase{
+		Name:               *wg.WorkgroupName + "-" + *endpoint.EndpointName,
+		URI:                formatHostPort(*endpoint.Address, endpointPort(endpoint.Port)),
+		Labels:             labels,
+		RedshiftServerless: rs,
+		AuthAPI:            RedshiftAuthAPIForDatabase(rs),
+	}, nil
+}
+
+func redshiftServerlessLabels(namespaceName, workgroupName string, tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(tags)+2)
+	for k, v := range tags {
+		labels[k] = v
+	}
+	if namespaceName != "" {
+		labels[DiscoveryLabelNamespaceName] = namespaceName
+	}
+	labels[DiscoveryLabelWorkgroupName] = workgroupName
+	return labels
+}
+
+func endpointPort(port *int64) int64 {
+	if port == nil {
+		return 5439
+	}
+	return *port
+}
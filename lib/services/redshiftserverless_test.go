@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseFromRedshiftServerlessWorkgroup(t *testing.T) {
+	wg := &redshiftserverless.Workgroup{
+		WorkgroupName: aws.String("my-workgroup"),
+		WorkgroupId:   aws.String("wg-id-123"),
+		NamespaceName: aws.String("my-namespace"),
+		Endpoint: &redshiftserverless.Endpoint{
+			Address: aws.String("my-workgroup.123456789012.eu-west-2.redshift-serverless.amazonaws.com"),
+			Port:    aws.Int64(5439),
+		},
+	}
+
+	db, err := NewDatabaseFromRedshiftServerlessWorkgroup(wg, nil, map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, "my-workgroup", db.Name)
+	require.Equal(t, "my-workgroup.123456789012.eu-west-2.redshift-serverless.amazonaws.com:5439", db.URI)
+	require.Equal(t, "my-namespace", db.Labels[DiscoveryLabelNamespaceName])
+	require.Equal(t, "my-workgroup", db.Labels[DiscoveryLabelWorkgroupName])
+	require.Equal(t, "prod", db.Labels["env"])
+	require.Equal(t, "wg-id-123", db.RedshiftServerless.WorkgroupID)
+	require.Equal(t, RedshiftAuthAPIGetCredentials, db.AuthAPI)
+}
+
+func TestNewDatabaseFromRedshiftServerlessWorkgroupMissingEndpoint(t *testing.T) {
+	_, err := NewDatabaseFromRedshiftServerlessWorkgroup(&redshiftserverless.Workgroup{WorkgroupName: aws.String("my-workgroup")}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestNewDatabaseFromRedshiftServerlessVPCEndpoint(t *testing.T) {
+	wg := &redshiftserverless.Workgroup{
+		WorkgroupName: aws.String("my-workgroup"),
+		WorkgroupId:   aws.String("wg-id-123"),
+		NamespaceName: aws.String("my-namespace"),
+	}
+	endpoint := &redshiftserverless.EndpointAccess{
+		EndpointName: aws.String("my-endpoint"),
+		Address:      aws.String("my-endpoint-xxxyyyzzz.123456789012.eu-west-2.redshift-serverless.amazonaws.com"),
+		Port:         aws.Int64(5439),
+	}
+
+	db, err := NewDatabaseFromRedshiftServerlessVPCEndpoint(endpoint, wg, nil, map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Equal(t, "my-workgroup-my-endpoint", db.Name)
+	require.Equal(t, "my-endpoint-xxxyyyzzz.123456789012.eu-west-2.redshift-serverless.amazonaws.com:5439", db.URI)
+	require.Equal(t, "my-namespace", db.Labels[DiscoveryLabelNamespaceName])
+	require.Equal(t, "my-workgroup", db.Labels[DiscoveryLabelWorkgroupName])
+	require.Equal(t, "my-endpoint", db.RedshiftServerless.EndpointName)
+	require.Equal(t, RedshiftAuthAPIGetCredentials, db.AuthAPI)
+}
+
+func TestNewDatabaseFromRedshiftServerlessVPCEndpointMissingWorkgroup(t *testing.T) {
+	endpoint := &redshiftserverless.EndpointAccess{
+		EndpointName: aws.String("my-endpoint"),
+		Address:      aws.String("endpoint.example.com"),
+	}
+	_, err := NewDatabaseFromRedshiftServerlessVPCEndpoint(endpoint, nil, nil, nil)
+	require.Error(t, err)
+}
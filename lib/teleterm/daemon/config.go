@@ -0,0 +1,86 @@
+// Copyright 2021 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/teleterm/clusters"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/connbroker"
+	"github.com/gravitational/teleport/lib/teleterm/services/connectmycomputer"
+)
+
+// Config describes the dependencies daemon.Service needs in order to run.
+type Config struct {
+	// Storage resolves a resource URI to the cluster and client managing it.
+	Storage *clusters.Storage
+	// GatewayCreator creates gateways to cluster resources (databases, kube
+	// clusters, apps).
+	GatewayCreator GatewayCreator
+	// KubeconfigsDir is the directory where kube gateways write the
+	// kubeconfig files they hand to kubectl.
+	KubeconfigsDir string
+	// CreateTshdEventsClientCredsFunc returns the transport credentials used
+	// to dial the tshd events server run by the Electron app.
+	CreateTshdEventsClientCredsFunc func() (grpc.DialOption, error)
+	// PrehogAddr is the address of the usage-reporting prehog server.
+	PrehogAddr string
+	// ConnectMyComputerRoleSetup sets up the role used by Connect My Computer.
+	ConnectMyComputerRoleSetup ConnectMyComputerRoleSetup
+	// ConnectMyComputerTokenProvisioner creates and deletes the node join
+	// token used by Connect My Computer.
+	ConnectMyComputerTokenProvisioner ConnectMyComputerTokenProvisioner
+	// Log is used for daemon-wide logging.
+	Log logrus.FieldLogger
+	// PanicHandlers are invoked, in order, whenever safeGo recovers a panic
+	// in a Service-managed background goroutine, in addition to the default
+	// logging and tshdEventsClient notification. Tests use this to assert
+	// that a crash was observed without having to parse log output.
+	PanicHandlers []func(name string, err error)
+}
+
+// ConnectMyComputerRoleSetup sets up the role used by Connect My Computer.
+type ConnectMyComputerRoleSetup interface {
+	Run(ctx context.Context, authClient client.AuthClient, proxyClient connbroker.ProxyClient, cluster *clusters.Cluster) (*connectmycomputer.RoleSetupResult, error)
+}
+
+// ConnectMyComputerTokenProvisioner creates and deletes the node join token
+// used by Connect My Computer.
+type ConnectMyComputerTokenProvisioner interface {
+	CreateNodeToken(ctx context.Context, authClient client.AuthClient, cluster *clusters.Cluster) (*connectmycomputer.NodeToken, error)
+	DeleteToken(ctx context.Context, authClient client.AuthClient, token string) error
+}
+
+// CheckAndSetDefaults validates the config and sets defaults where possible.
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Storage == nil {
+		return trace.BadParameter("Storage is required")
+	}
+	if c.GatewayCreator == nil {
+		return trace.BadParameter("GatewayCreator is required")
+	}
+	if c.CreateTshdEventsClientCredsFunc == nil {
+		return trace.BadParameter("CreateTshdEventsClientCredsFunc is required")
+	}
+	if c.Log == nil {
+		c.Log = logrus.WithField("component", "daemon")
+	}
+	return nil
+}
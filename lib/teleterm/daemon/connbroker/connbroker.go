@@ -0,0 +1,274 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connbroker hands out reference-counted, health-checked
+// proxy+auth client connections keyed by root cluster URI. It exists so
+// that daemon.Service methods that need to talk to the cluster (relogin,
+// cert reissue, Connect My Computer flows) don't each pay the full TLS +
+// gRPC dial cost and don't fan out a new proxy connection per request.
+package connbroker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/lib/client"
+)
+
+const (
+	// defaultIdleTTL is how long an unused lease is kept warm before the
+	// broker closes the underlying connections.
+	defaultIdleTTL = 5 * time.Minute
+	// defaultMaxInFlight bounds the number of concurrent callers that can be
+	// dialing a connection for the same cluster at once; additional callers
+	// wait for the in-flight dial to finish and then reuse its result.
+	defaultMaxInFlight = 4
+)
+
+// ProxyClient is the subset of client.ProxyClient the broker needs in order
+// to manage a connection's lifecycle.
+type ProxyClient interface {
+	ConnectToCluster(ctx context.Context, clusterName string) (client.AuthClient, error)
+	Close() error
+}
+
+// Dialer creates a fresh proxy connection for a cluster. In production
+// this is clusterClient.ConnectToProxy; tests can substitute a fake.
+type Dialer func(ctx context.Context) (ProxyClient, error)
+
+// Lease is a held reference to a pooled connection pair. Callers must call
+// Release exactly once when they're done with it.
+type Lease struct {
+	Proxy ProxyClient
+	Auth  client.AuthClient
+
+	entry *entry
+}
+
+// Release returns the lease to the broker. It does not close the
+// underlying connections; they stay warm until the idle TTL elapses or the
+// cluster is evicted.
+func (l *Lease) Release() {
+	l.entry.release()
+}
+
+// Config configures a [Broker].
+type Config struct {
+	// IdleTTL is how long a connection pair is kept around after its last
+	// lease is released before being closed.
+	IdleTTL time.Duration
+	// MaxInFlight bounds concurrent dials per cluster.
+	MaxInFlight int
+	// Clock is used for the idle timer; defaults to the real clock.
+	Clock clockwork.Clock
+}
+
+func (c *Config) checkAndSetDefaults() {
+	if c.IdleTTL <= 0 {
+		c.IdleTTL = defaultIdleTTL
+	}
+	if c.MaxInFlight <= 0 {
+		c.MaxInFlight = defaultMaxInFlight
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+}
+
+// Broker hands out leased, pooled connections keyed by root cluster URI.
+type Broker struct {
+	cfg Config
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// New creates a [*Broker].
+func New(cfg Config) *Broker {
+	cfg.checkAndSetDefaults()
+	return &Broker{
+		cfg:     cfg,
+		entries: make(map[string]*entry),
+	}
+}
+
+// entry tracks one cluster's pooled connection and how many leases are
+// currently outstanding against it.
+type entry struct {
+	mu         sync.Mutex
+	broker     *Broker
+	clusterURI string
+
+	proxy ProxyClient
+	auth  client.AuthClient
+
+	refs      int
+	idleTimer clockwork.Timer
+
+	// dialSem bounds the number of callers concurrently dialing a fresh
+	// connection for this entry to Config.MaxInFlight; it's sized once, when
+	// the entry is created.
+	dialSem chan struct{}
+}
+
+// pinger is implemented by client.AuthClient in production. Select uses it
+// to detect a pooled connection that died since it was last leased, so
+// callers get a fresh dial instead of a connection that's bound to fail on
+// first use.
+type pinger interface {
+	Ping(ctx context.Context) (proto.PingResponse, error)
+}
+
+// isHealthy reports whether auth still looks usable. AuthClient
+// implementations that don't support Ping are assumed healthy, since there's
+// no cheaper way to check them.
+func isHealthy(ctx context.Context, auth client.AuthClient) bool {
+	p, ok := auth.(pinger)
+	if !ok {
+		return true
+	}
+	_, err := p.Ping(ctx)
+	return err == nil
+}
+
+// Select returns a leased proxy+auth pair for rootClusterURI, dialing a new
+// connection if none is currently pooled (or if the pooled one is
+// unhealthy). Concurrent callers for the same cluster share the in-flight
+// dial rather than each starting their own, up to Config.MaxInFlight
+// outstanding dials.
+func (b *Broker) Select(ctx context.Context, rootClusterURI string, siteName string, dial Dialer) (*Lease, error) {
+	b.mu.Lock()
+	e, ok := b.entries[rootClusterURI]
+	if !ok {
+		e = &entry{broker: b, clusterURI: rootClusterURI, dialSem: make(chan struct{}, b.cfg.MaxInFlight)}
+		b.entries[rootClusterURI] = e
+	}
+	b.mu.Unlock()
+
+	// Fast path: reuse the pooled connection without waiting on the dial
+	// semaphore, unless it's gone unhealthy since it was last leased.
+	e.mu.Lock()
+	if e.idleTimer != nil {
+		e.idleTimer.Stop()
+		e.idleTimer = nil
+	}
+	if e.proxy != nil && isHealthy(ctx, e.auth) {
+		e.refs++
+		lease := &Lease{Proxy: e.proxy, Auth: e.auth, entry: e}
+		e.mu.Unlock()
+		return lease, nil
+	}
+	e.mu.Unlock()
+
+	// Redialing (or dialing for the first time) is bounded to MaxInFlight
+	// concurrent attempts per cluster; additional callers wait here.
+	select {
+	case e.dialSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, trace.Wrap(ctx.Err())
+	}
+	defer func() { <-e.dialSem }()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.idleTimer != nil {
+		e.idleTimer.Stop()
+		e.idleTimer = nil
+	}
+
+	if e.proxy != nil && !isHealthy(ctx, e.auth) {
+		e.auth.Close()
+		e.proxy.Close()
+		e.proxy = nil
+		e.auth = nil
+	}
+
+	if e.proxy == nil {
+		proxy, err := dial(ctx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		auth, err := proxy.ConnectToCluster(ctx, siteName)
+		if err != nil {
+			proxy.Close()
+			return nil, trace.Wrap(err)
+		}
+
+		e.proxy = proxy
+		e.auth = auth
+	}
+
+	e.refs++
+	return &Lease{Proxy: e.proxy, Auth: e.auth, entry: e}, nil
+}
+
+func (e *entry) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.refs--
+	if e.refs > 0 {
+		return
+	}
+
+	e.idleTimer = e.broker.cfg.Clock.AfterFunc(e.broker.cfg.IdleTTL, func() {
+		e.evict()
+	})
+}
+
+func (e *entry) evict() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.refs > 0 || e.proxy == nil {
+		return
+	}
+
+	if e.auth != nil {
+		e.auth.Close()
+	}
+	e.proxy.Close()
+	e.proxy = nil
+	e.auth = nil
+
+	e.broker.mu.Lock()
+	delete(e.broker.entries, e.clusterURI)
+	e.broker.mu.Unlock()
+}
+
+// Evict immediately closes and forgets any pooled connection for
+// rootClusterURI, regardless of its idle timer. Service hooks this into
+// ClusterLogout and RemoveCluster so a logged-out cluster doesn't keep a
+// stale connection warm.
+func (b *Broker) Evict(rootClusterURI string) {
+	b.mu.Lock()
+	e, ok := b.entries[rootClusterURI]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	e.refs = 0
+	e.mu.Unlock()
+
+	e.evict()
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connbroker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/lib/client"
+)
+
+// fakeAuthClient embeds client.AuthClient so it satisfies the interface
+// without implementing every method; only Ping and Close are overridden,
+// which is all Select/release ever call on it.
+type fakeAuthClient struct {
+	client.AuthClient
+
+	mu      sync.Mutex
+	pingErr error
+	closed  bool
+}
+
+func (f *fakeAuthClient) Ping(ctx context.Context) (proto.PingResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return proto.PingResponse{}, f.pingErr
+}
+
+func (f *fakeAuthClient) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeAuthClient) setUnhealthy() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingErr = trace.ConnectionProblem(nil, "connection is no longer healthy")
+}
+
+type fakeProxyClient struct {
+	auth   *fakeAuthClient
+	closed bool
+}
+
+func (f *fakeProxyClient) ConnectToCluster(ctx context.Context, clusterName string) (client.AuthClient, error) {
+	return f.auth, nil
+}
+
+func (f *fakeProxyClient) Close() error {
+	f.closed = true
+	return nil
+}
+
+// newCountingDialer returns a Dialer that hands out a fresh fakeProxyClient
+// on each call and tracks how many times it was invoked, plus the current
+// and maximum number of concurrently in-flight calls.
+func newCountingDialer() (dial Dialer, calls *int32, maxConcurrent *int32) {
+	var count, inFlight, maxInFlight int32
+	dial = func(ctx context.Context) (ProxyClient, error) {
+		atomic.AddInt32(&count, 1)
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if cur <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, cur) {
+				break
+			}
+		}
+		// Give concurrent callers a chance to pile up before returning.
+		time.Sleep(10 * time.Millisecond)
+		return &fakeProxyClient{auth: &fakeAuthClient{}}, nil
+	}
+	return dial, &count, &maxInFlight
+}
+
+func TestBrokerSelectReusesHealthyConnection(t *testing.T) {
+	b := New(Config{})
+	dial, calls, _ := newCountingDialer()
+
+	lease1, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	lease1.Release()
+
+	lease2, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	lease2.Release()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(calls), "second Select should reuse the pooled connection instead of redialing")
+	require.Same(t, lease1.Proxy, lease2.Proxy)
+}
+
+func TestBrokerSelectRedialsUnhealthyConnection(t *testing.T) {
+	b := New(Config{})
+	dial, calls, _ := newCountingDialer()
+
+	lease1, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	firstAuth := lease1.Auth.(*fakeAuthClient)
+	firstProxy := lease1.Proxy.(*fakeProxyClient)
+	lease1.Release()
+
+	firstAuth.setUnhealthy()
+
+	lease2, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	lease2.Release()
+
+	require.Equal(t, int32(2), atomic.LoadInt32(calls), "an unhealthy pooled connection should be redialed")
+	require.NotSame(t, lease1.Proxy, lease2.Proxy)
+	require.True(t, firstProxy.closed, "the unhealthy connection should be closed once replaced")
+}
+
+func TestBrokerSelectConcurrentDialsBoundedByMaxInFlight(t *testing.T) {
+	const maxInFlight = 2
+	b := New(Config{MaxInFlight: maxInFlight})
+	dial, calls, maxConcurrent := newCountingDialer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+			require.NoError(t, err)
+			lease.Release()
+		}()
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, atomic.LoadInt32(maxConcurrent), int32(maxInFlight),
+		"no more than MaxInFlight dials should be in flight at once")
+	require.GreaterOrEqual(t, atomic.LoadInt32(calls), int32(1))
+}
+
+func TestBrokerSelectConcurrentSharesPooledConnection(t *testing.T) {
+	b := New(Config{})
+	dial, calls, _ := newCountingDialer()
+
+	// Prime the pool with one healthy connection.
+	primer, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	primer.Release()
+	require.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+			require.NoError(t, err)
+			lease.Release()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(calls), "concurrent callers should reuse the already-healthy pooled connection")
+}
+
+func TestBrokerEvictClosesRegardlessOfOutstandingLease(t *testing.T) {
+	b := New(Config{})
+	dial, calls, _ := newCountingDialer()
+
+	lease, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	proxy := lease.Proxy.(*fakeProxyClient)
+
+	b.Evict("cluster1")
+	require.True(t, proxy.closed)
+
+	lease2, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	lease2.Release()
+	require.Equal(t, int32(2), atomic.LoadInt32(calls), "Select after Evict should dial a fresh connection")
+}
+
+func TestBrokerIdleConnectionIsEvictedAfterTTL(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	b := New(Config{IdleTTL: time.Minute, Clock: clock})
+	dial, calls, _ := newCountingDialer()
+
+	lease, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	proxy := lease.Proxy.(*fakeProxyClient)
+	lease.Release()
+
+	clock.Advance(time.Minute + time.Second)
+	require.Eventually(t, func() bool { return proxy.closed }, time.Second, time.Millisecond,
+		"pooled connection should be closed once IdleTTL elapses with no outstanding leases")
+
+	lease2, err := b.Select(context.Background(), "cluster1", "cluster1", dial)
+	require.NoError(t, err)
+	lease2.Release()
+	require.Equal(t, int32(2), atomic.LoadInt32(calls), "Select after idle eviction should dial a fresh connection")
+}
@@ -16,12 +16,13 @@ package daemon
 
 import (
 	"context"
+	"fmt"
 	"os/exec"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/gravitational/trace"
-	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -31,6 +32,12 @@ import (
 	"github.com/gravitational/teleport/lib/teleterm/api/uri"
 	"github.com/gravitational/teleport/lib/teleterm/clusters"
 	"github.com/gravitational/teleport/lib/teleterm/cmd"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/connbroker"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/eventbroker"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/modalqueue"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/supervisor"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/tshdevents"
+	"github.com/gravitational/teleport/lib/teleterm/daemon/workqueue"
 	"github.com/gravitational/teleport/lib/teleterm/gateway"
 	"github.com/gravitational/teleport/lib/teleterm/services/connectmycomputer"
 	usagereporter "github.com/gravitational/teleport/lib/usagereporter/daemon"
@@ -46,11 +53,24 @@ const (
 	// imporantModalWaitDuraiton is the amount of time to wait between sending tshd events that
 	// display important modals in the Electron App. This ensures a clear transition between modals.
 	imporantModalWaitDuraiton = time.Second / 2
+)
 
-	// The Electron App can only display one important modal at a time.
-	maxConcurrentImportantModals = 1
+const (
+	// modalKindRelogin is the important-modal kind used by relogin.
+	modalKindRelogin modalqueue.Kind = "relogin"
+	// modalKindReauthWarning is the important-modal kind used to warn the
+	// user their session is about to expire; unlike relogin it can be
+	// preempted, since a relogin request arriving while it's shown makes it
+	// moot.
+	modalKindReauthWarning modalqueue.Kind = "reauth-warning"
 )
 
+// modalPreemptible reports whether the active modal of the given kind may be
+// preempted by a strictly higher-priority request.
+func modalPreemptible(kind modalqueue.Kind) bool {
+	return kind == modalKindReauthWarning
+}
+
 // New creates an instance of Daemon service
 func New(cfg Config) (*Service, error) {
 	if err := cfg.CheckAndSetDefaults(); err != nil {
@@ -65,16 +85,85 @@ func New(cfg Config) (*Service, error) {
 		return nil, trace.Wrap(err)
 	}
 
-	go connectUsageReporter.Run(closeContext)
+	s := &Service{
+		cfg:                      &cfg,
+		closeContext:             closeContext,
+		cancel:                   cancel,
+		gateways:                 make(map[string]gateway.Gateway),
+		gatewaySupervisorCancels: make(map[string]context.CancelFunc),
+		sharedGateways:           make(map[string]*sharedGateway),
+		usageReporter:            connectUsageReporter,
+		headlessWatcherClosers:   make(map[string]context.CancelFunc),
+		connBroker:               connbroker.New(connbroker.Config{}),
+		workqueue:                workqueue.New(workqueue.Config{Log: cfg.Log}),
+	}
+	s.supervisor = supervisor.New(supervisor.Config{
+		Log:       cfg.Log,
+		OnCrash:   s.notifyAppOfCrash,
+		WaitGroup: &s.wg,
+	})
+	s.events = eventbroker.New(eventbroker.Config{})
 
-	return &Service{
-		cfg:                    &cfg,
-		closeContext:           closeContext,
-		cancel:                 cancel,
-		gateways:               make(map[string]gateway.Gateway),
-		usageReporter:          connectUsageReporter,
-		headlessWatcherClosers: make(map[string]context.CancelFunc),
-	}, nil
+	s.supervisor.Go(closeContext, "usage-reporter", func(ctx context.Context) error {
+		connectUsageReporter.Run(ctx)
+		return nil
+	})
+
+	return s, nil
+}
+
+// notifyAppOfCrash reports a recovered background-worker panic to the
+// Electron app so it isn't silently lost.
+func (s *Service) notifyAppOfCrash(name string, err error) {
+	notifyErr := s.notifyApp(s.closeContext, &api.SendNotificationRequest{
+		Subject: &api.SendNotificationRequest_CannotProxyGatewayConnection{
+			CannotProxyGatewayConnection: &api.CannotProxyGatewayConnection{
+				Error: fmt.Sprintf("background worker %q crashed: %v", name, err),
+			},
+		},
+	})
+	if notifyErr != nil {
+		s.cfg.Log.WithError(notifyErr).Errorf("Failed to notify app about a crash in %q", name)
+	}
+}
+
+// safeGo runs fn in a new goroutine tracked by s.wg, recovering any panic so
+// that a crashing headless watcher, gateway monitor, or usage-reporter flush
+// can't silently take down the rest of tshd. A recovered panic is logged
+// with name and a stack trace, reported to the Electron app the same way
+// notifyAppOfCrash reports a supervisor worker crash, and handed to every
+// Config.PanicHandlers entry so tests can assert it was observed.
+//
+// Unlike supervisor.Go, fn runs exactly once and is not restarted; use
+// s.supervisor.Go for background work that should keep running after a
+// crash.
+func (s *Service) safeGo(name string, fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				err := fmt.Errorf("panic in %q: %v\n%s", name, r, debug.Stack())
+				s.cfg.Log.WithField("worker", name).Error(err)
+				s.notifyAppOfCrash(name, err)
+				for _, handler := range s.cfg.PanicHandlers {
+					handler(name, err)
+				}
+			}
+		}()
+		fn()
+	}()
+}
+
+// connectToCluster leases a pooled proxy+auth client pair for the cluster's
+// root cluster URI from s.connBroker instead of dialing a fresh connection
+// on every call; the lease must be released once the caller is done with it.
+func (s *Service) connectToCluster(ctx context.Context, cluster *clusters.Cluster, clusterClient *client.TeleportClient) (*connbroker.Lease, error) {
+	lease, err := s.connBroker.Select(ctx, cluster.URI.GetRootClusterURI().String(), clusterClient.SiteName,
+		func(ctx context.Context) (connbroker.ProxyClient, error) {
+			return clusterClient.ConnectToProxy(ctx)
+		})
+	return lease, trace.Wrap(err)
 }
 
 // relogin makes the Electron app display a login modal to trigger re-login.
@@ -86,16 +175,17 @@ func (s *Service) relogin(ctx context.Context, req *api.ReloginRequest) error {
 	}
 	defer s.reloginMu.Unlock()
 
-	if err := s.importantModalSemaphore.Acquire(ctx); err != nil {
+	modalCtx, release, err := s.modalQueue.AcquireModal(ctx, modalqueue.PriorityHigh, modalKindRelogin)
+	if err != nil {
 		return trace.Wrap(err)
 	}
-	defer s.importantModalSemaphore.Release()
+	defer release()
 
 	const reloginUserTimeout = time.Minute
-	timeoutCtx, cancelTshdEventsCtx := context.WithTimeout(ctx, reloginUserTimeout)
+	timeoutCtx, cancelTshdEventsCtx := context.WithTimeout(modalCtx, reloginUserTimeout)
 	defer cancelTshdEventsCtx()
 
-	if _, err := s.tshdEventsClient.Relogin(timeoutCtx, req); err != nil {
+	if err := s.tshdEventsClient.Relogin(timeoutCtx, req); err != nil {
 		if status.Code(err) == codes.DeadlineExceeded {
 			return trace.Wrap(err, "the user did not refresh the session within %s", reloginUserTimeout.String())
 		}
@@ -191,6 +281,9 @@ func (s *Service) RemoveCluster(ctx context.Context, uri string) error {
 		return trace.Wrap(err)
 	}
 
+	s.connBroker.Evict(cluster.URI.GetRootClusterURI().String())
+	s.workqueue.CancelCluster(cluster.URI.GetRootClusterURI().String())
+
 	return nil
 }
 
@@ -243,6 +336,9 @@ func (s *Service) ClusterLogout(ctx context.Context, uri string) error {
 		return trace.Wrap(err)
 	}
 
+	s.connBroker.Evict(cluster.URI.GetRootClusterURI().String())
+	s.workqueue.CancelCluster(cluster.URI.GetRootClusterURI().String())
+
 	return nil
 }
 
@@ -270,7 +366,7 @@ func (s *Service) createGateway(ctx context.Context, params CreateGatewayParams)
 		return nil, trace.Wrap(err)
 	}
 
-	if gateway, ok := s.shouldReuseGateway(targetURI); ok {
+	if gateway, ok := s.shouldReuseGateway(targetURI, params.TargetSubresourceName); ok {
 		return gateway, nil
 	}
 
@@ -288,13 +384,36 @@ func (s *Service) createGateway(ctx context.Context, params CreateGatewayParams)
 		return nil, trace.Wrap(err)
 	}
 
-	go func() {
+	// The supervised goroutine is tied to a context scoped to this gateway's
+	// own lifetime, not the long-lived daemon context: canceling it (done by
+	// removeGateway) is how the supervisor is told that Serve() returning is
+	// an intentional close rather than a crash to restart.
+	gatewayCtx, cancel := context.WithCancel(s.closeContext)
+	s.gatewaySupervisorCancels[gateway.URI().String()] = cancel
+
+	s.supervisor.Go(gatewayCtx, "gateway/"+gateway.URI().String(), func(context.Context) error {
+		s.events.Publish(eventbroker.Event{
+			Topic: eventbroker.GatewayTopic(gateway.URI().String()),
+			Kind:  "gateway.opened",
+		})
 		if err := gateway.Serve(); err != nil {
 			gateway.Log().WithError(err).Warn("Failed to handle a gateway connection.")
+			s.events.Publish(eventbroker.Event{
+				Topic:   eventbroker.GatewayTopic(gateway.URI().String()),
+				Kind:    "gateway.closed",
+				Payload: err.Error(),
+			})
+			return err
 		}
-	}()
+		s.events.Publish(eventbroker.Event{
+			Topic: eventbroker.GatewayTopic(gateway.URI().String()),
+			Kind:  "gateway.closed",
+		})
+		return nil
+	})
 
 	s.gateways[gateway.URI().String()] = gateway
+	s.sharedGateways[sharedGatewayKey(targetURI, params.TargetSubresourceName)] = &sharedGateway{gw: gateway, refs: 1}
 
 	return gateway, nil
 }
@@ -330,7 +449,23 @@ func (s *Service) reissueGatewayCerts(ctx context.Context, g gateway.Gateway) er
 	// This can happen if the user cert was refreshed by anything other than the gateway itself. For
 	// example, if you execute `tsh ssh` within Connect after your user cert expires or there are two
 	// gateways that subsequently go through this flow.
-	if err := s.retryWithRelogin(ctx, reloginReq, reissueDBCerts); err != nil {
+	//
+	// This is submitted through s.workqueue, keyed by the gateway's root cluster, so that several
+	// gateways hitting an expired cert on the same flaky cluster at once don't each independently
+	// hammer it with reconnect attempts; the queue serializes them and backs off on failure.
+	clusterKey := g.TargetURI().GetRootClusterURI().String()
+	result := s.workqueue.Submit(clusterKey, "gateway/reissue-certs/"+g.URI().String(), func(ctx context.Context) error {
+		return s.retryWithRelogin(ctx, reloginReq, reissueDBCerts)
+	})
+
+	var reissueErr error
+	select {
+	case reissueErr = <-result:
+	case <-ctx.Done():
+		reissueErr = ctx.Err()
+	}
+
+	if err := reissueErr; err != nil {
 		notifyErr := s.notifyApp(ctx, &api.SendNotificationRequest{
 			Subject: &api.SendNotificationRequest_CannotProxyGatewayConnection{
 				CannotProxyGatewayConnection: &api.CannotProxyGatewayConnection{
@@ -344,27 +479,66 @@ func (s *Service) reissueGatewayCerts(ctx context.Context, g gateway.Gateway) er
 			s.cfg.Log.WithError(notifyErr).Error("Failed to send a notification for an error encountered during gateway cert reissue")
 		}
 
+		s.events.Publish(eventbroker.Event{
+			Topic:   eventbroker.GatewayTopic(g.URI().String()),
+			Kind:    "gateway.certs_reissue_failed",
+			Payload: err.Error(),
+		})
+
 		// Return the error to the alpn.LocalProxy's middleware.
 		return trace.Wrap(err)
 	}
 
+	s.events.Publish(eventbroker.Event{
+		Topic: eventbroker.GatewayTopic(g.URI().String()),
+		Kind:  "gateway.certs_reissued",
+	})
+
 	return nil
 }
 
-// RemoveGateway removes cluster gateway
+// RemoveGateway releases the caller's subscription to a gateway. If the
+// gateway is shared with other subscribers (for example another terminal
+// tab pointed at the same kube cluster), it keeps running; it's only closed
+// once the last subscriber lets go, and even then only after
+// sharedGatewayIdleGrace in case of an immediate resubscribe.
 func (s *Service) RemoveGateway(gatewayURI string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	gateway, err := s.findGateway(gatewayURI)
+	gw, err := s.findGateway(gatewayURI)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	if err := s.removeGateway(gateway); err != nil {
-		return trace.Wrap(err)
+	key := sharedGatewayKey(gw.TargetURI(), gw.TargetSubresourceName())
+	sg, ok := s.sharedGateways[key]
+	if !ok {
+		// Not tracked as a shared gateway; this shouldn't happen since
+		// createGateway always registers one, but fall back to closing it
+		// directly rather than leaking it.
+		return trace.Wrap(s.removeGateway(gw))
 	}
 
+	sg.refs--
+	if sg.refs > 0 {
+		return nil
+	}
+
+	sg.closeTimer = time.AfterFunc(sharedGatewayIdleGrace, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if current, ok := s.sharedGateways[key]; !ok || current != sg || sg.refs > 0 {
+			return
+		}
+
+		if err := s.removeGateway(gw); err != nil {
+			gw.Log().WithError(err).Warn("Failed to close an idle shared gateway.")
+		}
+		delete(s.sharedGateways, key)
+	})
+
 	return nil
 }
 
@@ -376,7 +550,15 @@ func (s *Service) removeGateway(gateway gateway.Gateway) error {
 		return trace.Wrap(err)
 	}
 
-	delete(s.gateways, gateway.URI().String())
+	// Cancel the supervised Serve goroutine's context so the supervisor
+	// treats this close as intentional instead of restarting it.
+	gatewayURI := gateway.URI().String()
+	if cancel, ok := s.gatewaySupervisorCancels[gatewayURI]; ok {
+		cancel()
+		delete(s.gatewaySupervisorCancels, gatewayURI)
+	}
+
+	delete(s.gateways, gatewayURI)
 
 	return nil
 }
@@ -436,6 +618,18 @@ func (s *Service) SetGatewayTargetSubresourceName(gatewayURI, targetSubresourceN
 		return nil, trace.Wrap(err)
 	}
 
+	oldKey := sharedGatewayKey(gateway.TargetURI(), gateway.TargetSubresourceName())
+	newKey := sharedGatewayKey(gateway.TargetURI(), targetSubresourceName)
+
+	if sg, ok := s.sharedGateways[oldKey]; ok && oldKey != newKey {
+		if existing, ok := s.sharedGateways[newKey]; ok && existing != sg {
+			return nil, trace.AlreadyExists(
+				"a gateway is already shared for the same target and subresource %q", targetSubresourceName)
+		}
+		delete(s.sharedGateways, oldKey)
+		s.sharedGateways[newKey] = sg
+	}
+
 	gateway.SetTargetSubresourceName(targetSubresourceName)
 
 	return gateway, nil
@@ -485,11 +679,20 @@ func (s *Service) SetGatewayLocalPort(gatewayURI, localPort string) (gateway.Gat
 
 	s.gateways[gatewayURI] = newGateway
 
-	go func() {
+	if sg, ok := s.sharedGateways[sharedGatewayKey(newGateway.TargetURI(), newGateway.TargetSubresourceName())]; ok {
+		sg.gw = newGateway
+	}
+
+	gatewayCtx, cancel := context.WithCancel(s.closeContext)
+	s.gatewaySupervisorCancels[newGateway.URI().String()] = cancel
+
+	s.supervisor.Go(gatewayCtx, "gateway/"+newGateway.URI().String(), func(context.Context) error {
 		if err := newGateway.Serve(); err != nil {
 			newGateway.Log().WithError(err).Warn("Failed to handle a gateway connection.")
+			return err
 		}
-	}()
+		return nil
+	})
 
 	return newGateway, nil
 }
@@ -644,16 +847,18 @@ func (s *Service) ReportUsageEvent(req *api.ReportUsageEventRequest) error {
 	return nil
 }
 
-// Stop terminates all cluster open connections
-func (s *Service) Stop() {
+// Stop terminates all cluster connections and background goroutines. It
+// cancels closeContext, closes every gateway and headless watcher, then
+// waits for every goroutine tracked in s.wg (gateways, the usage reporter,
+// and anything started through s.goroutine) to exit, bounded by ctx.
+func (s *Service) Stop(ctx context.Context) error {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	s.cfg.Log.Info("Stopping")
 
 	for _, gateway := range s.gateways {
 		gateway.Close()
 	}
+	s.mu.RUnlock()
 
 	s.StopHeadlessWatchers()
 
@@ -668,6 +873,19 @@ func (s *Service) Stop() {
 	// the resources managed by daemon.Service are up and running. So let's cancel the context only
 	// after closing those resources.
 	s.cancel()
+
+	wgDone := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		return nil
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err(), "background goroutines did not exit before Stop's context was done")
+	}
 }
 
 // UpdateAndDialTshdEventsServerAddress allows the Electron app to provide the tshd events server
@@ -680,20 +898,24 @@ func (s *Service) UpdateAndDialTshdEventsServerAddress(serverAddress string) err
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	withCreds, err := s.cfg.CreateTshdEventsClientCredsFunc()
-	if err != nil {
-		return trace.Wrap(err)
+	if s.tshdEventsClient == nil {
+		client, err := tshdevents.New(tshdevents.Config{
+			CreateCreds: s.cfg.CreateTshdEventsClientCredsFunc,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		s.tshdEventsClient = client
 	}
 
-	conn, err := grpc.Dial(serverAddress, withCreds)
-	if err != nil {
+	if err := s.tshdEventsClient.SetEndpoints([]string{serverAddress}); err != nil {
 		return trace.Wrap(err)
 	}
 
-	client := api.NewTshdEventsServiceClient(conn)
-
-	s.tshdEventsClient = client
-	s.importantModalSemaphore = newWaitSemaphore(maxConcurrentImportantModals, imporantModalWaitDuraiton)
+	s.modalQueue = modalqueue.New(modalqueue.Config{
+		WaitDuration: imporantModalWaitDuraiton,
+		Preemptible:  modalPreemptible,
+	})
 
 	// Resume headless watchers for any active login sessions.
 	if err := s.StartHeadlessWatchers(); err != nil {
@@ -703,12 +925,41 @@ func (s *Service) UpdateAndDialTshdEventsServerAddress(serverAddress string) err
 	return nil
 }
 
+// SetTshdEventsServerAddresses hands the tshd events client a new set of
+// candidate addresses, e.g. after the Electron renderer process reloads and
+// starts a new tshd events server on a different port. Unlike
+// UpdateAndDialTshdEventsServerAddress this does not reset the important
+// modal semaphore or restart headless watchers, since the daemon itself
+// isn't restarting.
+func (s *Service) SetTshdEventsServerAddresses(addresses []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tshdEventsClient == nil {
+		return trace.BadParameter("tshd events client has not been initialized yet")
+	}
+
+	return trace.Wrap(s.tshdEventsClient.SetEndpoints(addresses))
+}
+
+// TshdEventsServerDiagnostics returns the current health of every candidate
+// tshd events server endpoint, for the ListTshdEventsEndpoints diagnostics RPC.
+func (s *Service) TshdEventsServerDiagnostics() []tshdevents.EndpointStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.tshdEventsClient == nil {
+		return nil
+	}
+	return s.tshdEventsClient.Diagnostics()
+}
+
 // notifyApp sends a notification (usually an error) to the Electron App.
 func (s *Service) notifyApp(ctx context.Context, notification *api.SendNotificationRequest) error {
 	tshdEventsCtx, cancelTshdEventsCtx := context.WithTimeout(ctx, tshdEventsTimeout)
 	defer cancelTshdEventsCtx()
 
-	_, err := s.tshdEventsClient.SendNotification(tshdEventsCtx, notification)
+	err := s.tshdEventsClient.SendNotification(tshdEventsCtx, notification)
 	return trace.Wrap(err)
 }
 
@@ -731,19 +982,13 @@ func (s *Service) CreateConnectMyComputerRole(ctx context.Context, req *api.Crea
 	}
 	response := &api.CreateConnectMyComputerRoleResponse{}
 	err = clusters.AddMetadataToRetryableError(ctx, func() error {
-		proxyClient, err := clusterClient.ConnectToProxy(ctx)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		defer proxyClient.Close()
-
-		authClient, err := proxyClient.ConnectToCluster(ctx, clusterClient.SiteName)
+		lease, err := s.connectToCluster(ctx, cluster, clusterClient)
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		defer authClient.Close()
+		defer lease.Release()
 
-		result, err := s.cfg.ConnectMyComputerRoleSetup.Run(ctx, authClient, proxyClient, cluster)
+		result, err := s.cfg.ConnectMyComputerRoleSetup.Run(ctx, lease.Auth, lease.Proxy, cluster)
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -761,69 +1006,71 @@ func (s *Service) CreateConnectMyComputerNodeToken(ctx context.Context, rootClus
 		return nil, trace.Wrap(err)
 	}
 	var nodeToken *connectmycomputer.NodeToken
-	err = clusters.AddMetadataToRetryableError(ctx, func() error {
-		proxyClient, err := clusterClient.ConnectToProxy(ctx)
-		if err != nil {
+	result := s.workqueue.Submit(cluster.URI.GetRootClusterURI().String(), "connect-my-computer/create-node-token", func(ctx context.Context) error {
+		return clusters.AddMetadataToRetryableError(ctx, func() error {
+			lease, err := s.connectToCluster(ctx, cluster, clusterClient)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			defer lease.Release()
+
+			nodeToken, err = s.cfg.ConnectMyComputerTokenProvisioner.CreateNodeToken(ctx, lease.Auth, cluster)
 			return trace.Wrap(err)
-		}
-		defer proxyClient.Close()
-
-		authClient, err := proxyClient.ConnectToCluster(ctx, clusterClient.SiteName)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		defer authClient.Close()
-
-		nodeToken, err = s.cfg.ConnectMyComputerTokenProvisioner.CreateNodeToken(ctx, authClient, cluster)
-		return trace.Wrap(err)
+		})
 	})
 
-	return nodeToken, trace.Wrap(err)
+	select {
+	case err := <-result:
+		return nodeToken, trace.Wrap(err)
+	case <-ctx.Done():
+		return nil, trace.Wrap(ctx.Err())
+	}
 }
 
 // DeleteConnectMyComputerToken deletes a join token
 func (s *Service) DeleteConnectMyComputerToken(ctx context.Context, req *api.DeleteConnectMyComputerTokenRequest) (*api.DeleteConnectMyComputerTokenResponse, error) {
-	_, clusterClient, err := s.ResolveCluster(req.RootClusterUri)
+	cluster, clusterClient, err := s.ResolveCluster(req.RootClusterUri)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 	response := &api.DeleteConnectMyComputerTokenResponse{}
-	err = clusters.AddMetadataToRetryableError(ctx, func() error {
-		proxyClient, err := clusterClient.ConnectToProxy(ctx)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		defer proxyClient.Close()
-
-		authClient, err := proxyClient.ConnectToCluster(ctx, clusterClient.SiteName)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		defer authClient.Close()
-
-		err = s.cfg.ConnectMyComputerTokenProvisioner.DeleteToken(ctx, authClient, req.Token)
-		return trace.Wrap(err)
+	result := s.workqueue.Submit(cluster.URI.GetRootClusterURI().String(), "connect-my-computer/delete-token/"+req.Token, func(ctx context.Context) error {
+		return clusters.AddMetadataToRetryableError(ctx, func() error {
+			lease, err := s.connectToCluster(ctx, cluster, clusterClient)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			defer lease.Release()
+
+			return trace.Wrap(s.cfg.ConnectMyComputerTokenProvisioner.DeleteToken(ctx, lease.Auth, req.Token))
+		})
 	})
 
-	return response, trace.Wrap(err)
+	select {
+	case err := <-result:
+		return response, trace.Wrap(err)
+	case <-ctx.Done():
+		return nil, trace.Wrap(ctx.Err())
+	}
 }
 
-func (s *Service) shouldReuseGateway(targetURI uri.ResourceURI) (gateway.Gateway, bool) {
-	// A single gateway can be shared for all terminals of the same kube
-	// cluster.
-	if targetURI.IsKube() {
-		return s.findGatewayByTargetURI(targetURI)
+// shouldReuseGateway looks up a gateway already running for the same target
+// and subresource, bumping its subscriber count if one is found. Kube, app
+// access, and database terminals can all share a single tunnel this way when
+// they're pointed at the same target.
+func (s *Service) shouldReuseGateway(targetURI uri.ResourceURI, targetSubresourceName string) (gateway.Gateway, bool) {
+	sg, ok := s.sharedGateways[sharedGatewayKey(targetURI, targetSubresourceName)]
+	if !ok {
+		return nil, false
 	}
-	return nil, false
-}
 
-func (s *Service) findGatewayByTargetURI(targetURI uri.ResourceURI) (gateway.Gateway, bool) {
-	for _, gateway := range s.gateways {
-		if gateway.TargetURI() == targetURI {
-			return gateway, true
-		}
+	if sg.closeTimer != nil {
+		sg.closeTimer.Stop()
+		sg.closeTimer = nil
 	}
-	return nil, false
+	sg.refs++
+
+	return sg.gw, true
 }
 
 // Service is the daemon service
@@ -839,17 +1086,24 @@ type Service struct {
 	// gateways holds the long-running gateways for resources on different clusters. So far it's been
 	// used mostly for database gateways but it has potential to be used for app access as well.
 	gateways map[string]gateway.Gateway
-	// tshdEventsClient is a client to send events to the Electron App.
-	tshdEventsClient api.TshdEventsServiceClient
-	// The Electron App can only display one important Modal at a time. tshd events
-	// that trigger an important modal (relogin, headless login) should use this
-	// lock to ensure it doesn't overwrite existing tshd-initiated important modals.
+	// gatewaySupervisorCancels holds the cancel func for each gateway's
+	// supervised Serve goroutine, keyed by gateway URI. removeGateway calls it
+	// before closing the gateway so the supervisor treats the shutdown as
+	// intentional instead of restarting Serve on it.
+	gatewaySupervisorCancels map[string]context.CancelFunc
+	// tshdEventsClient is a health-checked, multi-endpoint client used to send
+	// events to the Electron App.
+	tshdEventsClient *tshdevents.Client
+	// The Electron App can only display one important modal at a time. tshd
+	// events that trigger an important modal (relogin, headless login)
+	// acquire a slot from modalQueue to ensure it doesn't overwrite existing
+	// tshd-initiated important modals.
 	//
-	// We use a semaphore instead of a mutex in order to cancel important modals that
-	// are no longer relevant before acquisition.
-	//
-	// We use a waitSemaphore in order to make sure there is a clear transition between modals.
-	importantModalSemaphore *waitSemaphore
+	// modalQueue serves requests in (priority, enqueue time) order rather
+	// than strict FIFO, and lets a strictly higher-priority, preemption
+	// -eligible request cancel the active modal's context so it's dismissed
+	// promptly instead of blocking behind it.
+	modalQueue *modalqueue.Scheduler
 	// usageReporter batches the events and sends them to prehog
 	usageReporter *usagereporter.UsageReporter
 	// reloginMu is used when a goroutine needs to request a relogin from the Electron app. Since the
@@ -858,41 +1112,103 @@ type Service struct {
 	// headlessWatcherClosers holds a map of root cluster URIs to headless watchers.
 	headlessWatcherClosers   map[string]context.CancelFunc
 	headlessWatcherClosersMu sync.Mutex
+	// connBroker hands out pooled, reference-counted proxy+auth connections
+	// keyed by root cluster URI, so relogin, cert reissue, and Connect My
+	// Computer flows don't each pay the cost of a fresh dial.
+	connBroker *connbroker.Broker
+	// supervisor runs every long-running background goroutine (gateways,
+	// headless watchers, the usage reporter) so that a panic in one of them
+	// doesn't crash the whole daemon.
+	supervisor *supervisor.Supervisor
+	// events publishes fine-grained gateway/cluster/Connect-My-Computer
+	// activity for the Electron app to stream, instead of relying solely on
+	// the one-shot notifyApp RPC.
+	events *eventbroker.Broker
+	// sharedGateways tracks, per target (and subresource), how many callers
+	// are currently subscribed to the gateway serving it, so a gateway shared
+	// by several terminals is only torn down once the last one lets go.
+	sharedGateways map[string]*sharedGateway
+	// wg is incremented for every long-running background goroutine Service
+	// starts (directly via goroutine, or indirectly through supervisor) and
+	// is waited on by Stop so shutdown doesn't race in-flight work.
+	wg sync.WaitGroup
+	// workqueue rate-limits and deduplicates retryable cluster RPCs (Connect
+	// My Computer token provisioning, gateway cert reissue) so a flaky proxy
+	// produces one backed-off retry loop per cluster instead of a thundering
+	// herd of concurrent attempts.
+	workqueue *workqueue.Queue
 }
 
-type CreateGatewayParams struct {
-	TargetURI             string
-	TargetUser            string
-	TargetSubresourceName string
-	LocalPort             string
+// goroutine runs fn in a new goroutine tracked by s.wg, for one-off
+// background work that doesn't need the restart semantics of supervisor.Go.
+func (s *Service) goroutine(name string, fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
 }
 
-// waitSemaphore is a semaphore that waits for a specified duration between acquisitions.
-type waitSemaphore struct {
-	semC         chan struct{}
-	lastRelease  time.Time
-	waitDuration time.Duration
+// sharedGateway wraps a gateway.Gateway that may be reused by more than one
+// caller pointed at the same target, mirroring Consul's ConfigSource watch
+// wrapper (a numWatchers count plus a way to tear the watch down once it
+// hits zero). refs is the number of outstanding CreateGateway calls that
+// haven't yet been matched by a RemoveGateway call.
+type sharedGateway struct {
+	gw   gateway.Gateway
+	refs int
+	// closeTimer is armed once refs drops to zero, giving a rapid
+	// resubscribe (for example a terminal tab closing and immediately
+	// reopening) a chance to reuse the gateway before it's torn down. It's
+	// disarmed if a new subscriber shows up first.
+	closeTimer *time.Timer
 }
 
-func newWaitSemaphore(maxConcurrency int, waitDuration time.Duration) *waitSemaphore {
-	return &waitSemaphore{
-		semC:         make(chan struct{}, maxConcurrency),
-		waitDuration: waitDuration,
-	}
+// sharedGatewayIdleGrace is how long a shared gateway with no subscribers is
+// kept alive before being closed, in case of an immediate resubscribe.
+const sharedGatewayIdleGrace = 10 * time.Second
+
+// sharedGatewayKey identifies the gateway that should be shared for a given
+// target and subresource (for example, an app access target's port).
+func sharedGatewayKey(targetURI uri.ResourceURI, targetSubresourceName string) string {
+	return targetURI.String() + "/" + targetSubresourceName
 }
 
-func (s *waitSemaphore) Acquire(ctx context.Context) error {
-	select {
-	case s.semC <- struct{}{}:
-		// wait up to the specified wait duration before returning.
-		time.Sleep(s.waitDuration - time.Since(s.lastRelease))
-		return nil
-	case <-ctx.Done():
-		return trace.Wrap(ctx.Err())
+// GatewaysMetrics reports the number of distinct running gateways versus the
+// total number of subscribers currently referencing them, for diagnostics.
+type GatewaysMetrics struct {
+	Gateways    int
+	Subscribers int
+}
+
+// GatewaysMetrics returns the current shared-gateway metrics.
+func (s *Service) GatewaysMetrics() GatewaysMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	metrics := GatewaysMetrics{Gateways: len(s.sharedGateways)}
+	for _, sg := range s.sharedGateways {
+		metrics.Subscribers += sg.refs
 	}
+	return metrics
+}
+
+// SubscribeToEvents returns a channel of events matching filter plus an
+// unsubscribe function; it backs the tshd-side SubscribeToEvents RPC.
+func (s *Service) SubscribeToEvents(ctx context.Context, filter func(eventbroker.Topic) bool) (<-chan eventbroker.Event, func()) {
+	return s.events.Subscribe(ctx, filter)
+}
+
+// ListDaemonWorkers returns the current status of every background worker
+// tracked by the daemon's supervisor, so the UI can surface unhealthy
+// background tasks instead of silently losing them.
+func (s *Service) ListDaemonWorkers() []supervisor.Worker {
+	return s.supervisor.List()
 }
 
-func (s *waitSemaphore) Release() {
-	s.lastRelease = time.Now()
-	<-s.semC
+type CreateGatewayParams struct {
+	TargetURI             string
+	TargetUser            string
+	TargetSubresourceName string
+	LocalPort             string
 }
@@ -0,0 +1,202 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eventbroker is a small pub/sub broker, modeled on swarmkit's log
+// broker, that lets daemon.Service stream fine-grained events (gateway
+// open/close, bytes proxied, cert TTL countdown, headless approval
+// requests, Connect My Computer node status) to the Electron app instead of
+// going through the one-shot, semaphore-gated notifyApp RPC.
+package eventbroker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSubscriberQueueSize bounds how many events a slow subscriber
+	// can fall behind by before older events are dropped.
+	defaultSubscriberQueueSize = 64
+	// defaultReplayBufferSize is how many of the most recent events per
+	// topic are kept so a subscriber that joins late still gets context.
+	defaultReplayBufferSize = 16
+)
+
+// Topic names a stream of events. Helpers below build the well-known
+// topics; callers should prefer them over constructing strings by hand.
+type Topic string
+
+// GatewayTopic returns the topic for events about a single gateway.
+func GatewayTopic(gatewayURI string) Topic { return Topic("gateway/" + gatewayURI) }
+
+// ClusterTopic returns the topic for events about a single cluster.
+func ClusterTopic(clusterURI string) Topic { return Topic("cluster/" + clusterURI) }
+
+// ConnectMyComputerTopic returns the topic for Connect My Computer node
+// status events scoped to a cluster.
+func ConnectMyComputerTopic(clusterURI string) Topic { return Topic("connectmycomputer/" + clusterURI) }
+
+// Event is a single message published to a topic.
+type Event struct {
+	Topic     Topic
+	Kind      string
+	Payload   any
+	Timestamp time.Time
+}
+
+// Config configures a [Broker].
+type Config struct {
+	// SubscriberQueueSize bounds the per-subscriber buffered channel.
+	SubscriberQueueSize int
+	// ReplayBufferSize bounds how many past events per topic are replayed
+	// to a new subscriber.
+	ReplayBufferSize int
+}
+
+func (c *Config) checkAndSetDefaults() {
+	if c.SubscriberQueueSize <= 0 {
+		c.SubscriberQueueSize = defaultSubscriberQueueSize
+	}
+	if c.ReplayBufferSize <= 0 {
+		c.ReplayBufferSize = defaultReplayBufferSize
+	}
+}
+
+// Broker fans out published events to subscribers filtered by topic
+// prefix, with a bounded per-subscriber queue (drop-oldest) and a replay
+// buffer per topic for late joiners.
+type Broker struct {
+	cfg Config
+
+	mu          sync.Mutex
+	subscribers map[*subscription]struct{}
+	replay      map[Topic][]Event
+}
+
+// New creates a [*Broker].
+func New(cfg Config) *Broker {
+	cfg.checkAndSetDefaults()
+	return &Broker{
+		cfg:         cfg,
+		subscribers: make(map[*subscription]struct{}),
+		replay:      make(map[Topic][]Event),
+	}
+}
+
+// Publish delivers event to every subscriber whose filter matches its
+// topic, and appends it to that topic's replay buffer.
+func (b *Broker) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	buf := append(b.replay[event.Topic], event)
+	if len(buf) > b.cfg.ReplayBufferSize {
+		buf = buf[len(buf)-b.cfg.ReplayBufferSize:]
+	}
+	b.replay[event.Topic] = buf
+
+	subs := make([]*subscription, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		if sub.matches(event.Topic) {
+			subs = append(subs, sub)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(event)
+	}
+}
+
+// subscription is a single subscriber's bounded, drop-oldest event queue.
+type subscription struct {
+	filter func(Topic) bool
+
+	mu     sync.Mutex
+	events chan Event
+}
+
+func (s *subscription) matches(topic Topic) bool {
+	return s.filter == nil || s.filter(topic)
+}
+
+// deliver enqueues event, dropping the oldest queued event if the
+// subscriber's queue is full rather than blocking the publisher.
+func (s *subscription) deliver(event Event) {
+	for {
+		select {
+		case s.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-s.events:
+		default:
+		}
+	}
+}
+
+// TopicFilter returns a filter that matches a topic exactly.
+func TopicFilter(topic Topic) func(Topic) bool {
+	return func(t Topic) bool { return t == topic }
+}
+
+// PrefixFilter returns a filter that matches any topic starting with
+// prefix (e.g. "gateway/" to receive events for every gateway).
+func PrefixFilter(prefix string) func(Topic) bool {
+	return func(t Topic) bool { return strings.HasPrefix(string(t), prefix) }
+}
+
+// Subscribe registers a new subscription matching filter and returns a
+// channel of events plus an unsubscribe function. The channel is replayed
+// with up to Config.ReplayBufferSize recent events per matching topic
+// before live events start arriving. The subscription is automatically
+// torn down when ctx is canceled, mirroring a gRPC server-streaming
+// handler's client-cancellation behavior.
+func (b *Broker) Subscribe(ctx context.Context, filter func(Topic) bool) (<-chan Event, func()) {
+	sub := &subscription{
+		filter: filter,
+		events: make(chan Event, b.cfg.SubscriberQueueSize),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	for topic, events := range b.replay {
+		if !sub.matches(topic) {
+			continue
+		}
+		for _, event := range events {
+			sub.deliver(event)
+		}
+	}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return sub.events, unsubscribe
+}
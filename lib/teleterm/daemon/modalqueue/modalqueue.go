@@ -0,0 +1,234 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modalqueue schedules the single "important modal" slot the
+// Electron app can display at once (relogin, headless approval, reauth
+// warning, ...). It replaces a plain capacity-1 semaphore, which serves
+// requests strictly FIFO, with a heap keyed by (priority, enqueue time) so a
+// high-priority request with a hard deadline (e.g. a headless approval)
+// doesn't sit behind a low-priority one already queued. A request can also
+// preempt the currently active modal if it's of strictly higher priority and
+// the active modal's kind allows it.
+package modalqueue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Priority ranks important-modal requests; higher values are served first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// Kind identifies the type of modal being requested, e.g. "relogin" or
+// "reauth-warning". Config.Preemptible decides, per kind, whether a request
+// of strictly higher priority can preempt it.
+type Kind string
+
+// Config configures a [Scheduler].
+type Config struct {
+	// WaitDuration is the minimum time to wait between handing the slot to
+	// one modal and the next, so the Electron app has a clear transition
+	// between them.
+	WaitDuration time.Duration
+	// Preemptible reports whether the currently active modal of the given
+	// kind may be preempted by a request of strictly higher priority.
+	// Defaults to "never" if unset.
+	Preemptible func(kind Kind) bool
+}
+
+func (c *Config) checkAndSetDefaults() {
+	if c.Preemptible == nil {
+		c.Preemptible = func(Kind) bool { return false }
+	}
+}
+
+// waiter is one pending or active AcquireModal call.
+type waiter struct {
+	priority   Priority
+	kind       Kind
+	seq        uint64
+	enqueuedAt time.Time
+	index      int
+	granted    chan struct{}
+	cancel     context.CancelFunc
+}
+
+// Scheduler hands out the single important-modal slot in priority order.
+type Scheduler struct {
+	cfg Config
+
+	mu          sync.Mutex
+	seq         uint64
+	queue       waiterHeap
+	active      *waiter
+	lastRelease time.Time
+}
+
+// New creates a [*Scheduler].
+func New(cfg Config) *Scheduler {
+	cfg.checkAndSetDefaults()
+	return &Scheduler{cfg: cfg}
+}
+
+// AcquireModal blocks until the caller is granted the important-modal slot
+// or ctx is canceled. A higher-priority request that arrives while this one
+// is still waiting is granted the slot first but does not cancel this
+// request, which keeps waiting. On success it returns a context derived from ctx that the caller
+// should use for the RPCs backing the modal: it's canceled if a
+// higher-priority, preemption-eligible request arrives while this modal is
+// active, signaling the caller to dismiss the modal and release the slot
+// promptly. The returned release func must be called exactly once.
+func (s *Scheduler) AcquireModal(ctx context.Context, priority Priority, kind Kind) (context.Context, func(), error) {
+	modalCtx, cancel := context.WithCancel(ctx)
+
+	w := &waiter{
+		priority:   priority,
+		kind:       kind,
+		enqueuedAt: time.Now(),
+		granted:    make(chan struct{}),
+		cancel:     cancel,
+	}
+
+	s.mu.Lock()
+	s.seq++
+	w.seq = s.seq
+	heap.Push(&s.queue, w)
+	s.preemptIfOutranked(w)
+	s.dispatch()
+	s.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return modalCtx, func() { s.release(w) }, nil
+	case <-ctx.Done():
+		// w.granted may have raced ctx.Done() and already been dispatched
+		// (dispatch sets s.active = w and closes granted under mu); in that
+		// case the slot must still be released here, or it's stuck until
+		// someone else calls release on an already-done w that never
+		// reaches its deferred release.
+		s.mu.Lock()
+		if s.active == w {
+			s.mu.Unlock()
+			s.release(w)
+			cancel()
+			return nil, nil, trace.Wrap(ctx.Err())
+		}
+		s.queue.removeWaiter(w)
+		s.mu.Unlock()
+		cancel()
+		return nil, nil, trace.Wrap(ctx.Err())
+	}
+}
+
+// preemptIfOutranked cancels the active modal's context if w strictly
+// outranks it and the active modal's kind allows preemption. The active
+// holder is expected to observe its context being canceled and release the
+// slot promptly; dispatch then hands it to the highest-priority waiter.
+func (s *Scheduler) preemptIfOutranked(w *waiter) {
+	if s.active == nil {
+		return
+	}
+	if w.priority > s.active.priority && s.cfg.Preemptible(s.active.kind) {
+		s.active.cancel()
+	}
+}
+
+// dispatch grants the slot to the highest-priority waiter if the slot is
+// free and the minimum wait since the last release has elapsed. Must be
+// called with mu held.
+func (s *Scheduler) dispatch() {
+	if s.active != nil || s.queue.Len() == 0 {
+		return
+	}
+
+	if !s.lastRelease.IsZero() {
+		if wait := s.cfg.WaitDuration - time.Since(s.lastRelease); wait > 0 {
+			time.AfterFunc(wait, func() {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				s.dispatch()
+			})
+			return
+		}
+	}
+
+	w := heap.Pop(&s.queue).(*waiter)
+	s.active = w
+	close(w.granted)
+}
+
+func (s *Scheduler) release(w *waiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != w {
+		return
+	}
+	s.active = nil
+	s.lastRelease = time.Now()
+	s.dispatch()
+}
+
+// waiterHeap is a container/heap max-heap ordered by priority, breaking ties
+// by enqueue order (lower seq first) so same-priority requests stay FIFO.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return w
+}
+
+// removeWaiter removes w from the heap if it's still queued (i.e. it hasn't
+// already been dispatched as active).
+func (h *waiterHeap) removeWaiter(w *waiter) {
+	if w.index < 0 || w.index >= h.Len() || (*h)[w.index] != w {
+		return
+	}
+	heap.Remove(h, w.index)
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package supervisor runs named background goroutines for tshd so that a
+// panic inside one (for example, inside gateway.Serve or a headless
+// watcher) doesn't crash the whole daemon and take Connect down with it.
+// It borrows the HandleCrash/Until pattern from Kubernetes controllers:
+// panics are recovered and logged with a stack trace, a crash callback is
+// invoked, and the function is optionally restarted with exponential
+// backoff up to a configurable cap.
+package supervisor
+
+import (
+	"context"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Status is the last known state of a supervised worker.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusCrashed Status = "crashed"
+	StatusStopped Status = "stopped"
+)
+
+// Worker describes one supervised goroutine for diagnostics purposes (it
+// backs the ListDaemonWorkers RPC).
+type Worker struct {
+	Name       string
+	Status     Status
+	Restarts   int
+	LastError  string
+	LastCrash  time.Time
+}
+
+// Config configures a [Supervisor].
+type Config struct {
+	// Log is used to report recovered panics; defaults to the standard
+	// logrus logger.
+	Log logrus.FieldLogger
+	// OnCrash is called after each failed run (a recovered panic or a
+	// returned error), before the restart wait begins. Typically wired to notifyApp so the Electron app can
+	// surface the failure.
+	OnCrash func(name string, err error)
+	// MaxBackoff caps the exponential backoff between restarts.
+	MaxBackoff time.Duration
+	// WaitGroup, if set, has Add(1)/Done() called around the full lifetime of
+	// each worker started with Go (i.e. until ctx is canceled or fn returns
+	// nil, not around each individual restart attempt), so a caller can wait
+	// for every supervised goroutine to exit during shutdown.
+	WaitGroup *sync.WaitGroup
+}
+
+func (c *Config) checkAndSetDefaults() {
+	if c.Log == nil {
+		c.Log = logrus.StandardLogger()
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+}
+
+// Supervisor runs and tracks named background goroutines.
+type Supervisor struct {
+	cfg Config
+
+	mu      sync.Mutex
+	workers map[string]*Worker
+}
+
+// New creates a [*Supervisor].
+func New(cfg Config) *Supervisor {
+	cfg.checkAndSetDefaults()
+	return &Supervisor{
+		cfg:     cfg,
+		workers: make(map[string]*Worker),
+	}
+}
+
+// Go runs fn in a new goroutine under the name tag. A panic in fn is
+// recovered and logged with a stack trace; a panic or a returned error is
+// recorded and reported through Config.OnCrash, and fn is then restarted with exponential
+// backoff (with jitter) until ctx is canceled. Go returns immediately; it
+// does not block on fn.
+func (s *Supervisor) Go(ctx context.Context, name string, fn func(context.Context) error) {
+	s.setStatus(name, StatusRunning)
+
+	if s.cfg.WaitGroup != nil {
+		s.cfg.WaitGroup.Add(1)
+	}
+
+	go func() {
+		if s.cfg.WaitGroup != nil {
+			defer s.cfg.WaitGroup.Done()
+		}
+
+		backoff := 500 * time.Millisecond
+
+		for {
+			err := s.runOnce(ctx, name, fn)
+
+			if ctx.Err() != nil {
+				s.setStatus(name, StatusStopped)
+				return
+			}
+			if err == nil {
+				s.setStatus(name, StatusStopped)
+				return
+			}
+
+			s.recordCrash(name, err)
+			if s.cfg.OnCrash != nil {
+				s.cfg.OnCrash(name, err)
+			}
+
+			wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+			select {
+			case <-ctx.Done():
+				s.setStatus(name, StatusStopped)
+				return
+			case <-time.After(wait):
+			}
+
+			backoff *= 2
+			if backoff > s.cfg.MaxBackoff {
+				backoff = s.cfg.MaxBackoff
+			}
+		}
+	}()
+}
+
+// runOnce executes fn once, converting a panic into an error so the caller
+// can apply the same restart/backoff logic to both panics and returned
+// errors.
+func (s *Supervisor) runOnce(ctx context.Context, name string, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.cfg.Log.WithField("worker", name).
+				Errorf("Recovered from panic: %v\n%s", r, debug.Stack())
+			err = &PanicError{Name: name, Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func (s *Supervisor) setStatus(name string, status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.workers[name]
+	if !ok {
+		w = &Worker{Name: name}
+		s.workers[name] = w
+	}
+	w.Status = status
+}
+
+func (s *Supervisor) recordCrash(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.workers[name]
+	if !ok {
+		w = &Worker{Name: name}
+		s.workers[name] = w
+	}
+	w.Status = StatusCrashed
+	w.Restarts++
+	w.LastError = err.Error()
+	w.LastCrash = time.Now()
+}
+
+// List returns a snapshot of every tracked worker, for ListDaemonWorkers.
+func (s *Supervisor) List() []Worker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Worker, 0, len(s.workers))
+	for _, w := range s.workers {
+		out = append(out, *w)
+	}
+	return out
+}
+
+// PanicError wraps a recovered panic value so it can be handled like any
+// other error by callers of Go.
+type PanicError struct {
+	Name  string
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return "panic in " + e.Name
+}
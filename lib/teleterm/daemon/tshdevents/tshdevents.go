@@ -0,0 +1,260 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tshdevents wraps the gRPC client used to call back into the
+// Electron app's tshd events server. Historically daemon.Service dialed a
+// single, fixed address with no health checking and no way to hand over a
+// new address without restarting tshd. Borrowing the health-balancer idea
+// from etcd's clientv3, Client accepts a list of candidate addresses, reads
+// each one's gRPC connectivity state when a call is made, tries healthy
+// endpoints first, and moves a failing endpoint to the back of the order for
+// a cooldown window. It sends no ping of its own.
+package tshdevents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	api "github.com/gravitational/teleport/gen/proto/go/teleport/lib/teleterm/v1"
+)
+
+const (
+	// defaultHealthCheckInterval is Config.HealthCheckInterval's default.
+	// Client does not sample or ping endpoints on a timer; it reads each
+	// endpoint's connectivity state when a call is made.
+	defaultHealthCheckInterval = 5 * time.Second
+	// defaultCooldown is how long an endpoint whose call failed is tried
+	// only after the healthy endpoints. It is not skipped.
+	defaultCooldown = 10 * time.Second
+)
+
+// CredsFunc returns the transport credentials dial option used to connect to
+// the tshd events server, mirroring daemon.Config.CreateTshdEventsClientCredsFunc.
+type CredsFunc func() (grpc.DialOption, error)
+
+// Config configures a [Client].
+type Config struct {
+	// CreateCreds returns the dial credentials to use for every endpoint.
+	CreateCreds CredsFunc
+	// HealthCheckInterval is stored but not used by Client, which reads
+	// endpoint health when a call is made rather than on an interval.
+	HealthCheckInterval time.Duration
+	// Cooldown is how long an endpoint whose call failed is ordered after
+	// the healthy endpoints; it is still tried if they all fail.
+	Cooldown time.Duration
+}
+
+func (c *Config) checkAndSetDefaults() error {
+	if c.CreateCreds == nil {
+		return trace.BadParameter("CreateCreds is required")
+	}
+	if c.HealthCheckInterval <= 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = defaultCooldown
+	}
+	return nil
+}
+
+// EndpointStatus reports the health of a single candidate endpoint, for the
+// diagnostics RPC.
+type EndpointStatus struct {
+	Address       string
+	State         connectivity.State
+	CooldownUntil time.Time
+}
+
+// endpoint is one candidate tshd events server address.
+type endpoint struct {
+	addr          string
+	conn          *grpc.ClientConn
+	client        api.TshdEventsServiceClient
+	cooldownUntil time.Time
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	if now.Before(e.cooldownUntil) {
+		return false
+	}
+	switch e.conn.GetState() {
+	case connectivity.Ready, connectivity.Idle, connectivity.Connecting:
+		return true
+	default:
+		return false
+	}
+}
+
+// Client dials a set of candidate tshd events server addresses and
+// transparently retries Relogin and SendNotification against the next
+// healthy endpoint when the current one is unreachable. Callers update the
+// candidate set through SetEndpoints as the Electron renderer hands over new
+// addresses on reload.
+type Client struct {
+	cfg Config
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+}
+
+// New creates a [*Client] with no endpoints. Call SetEndpoints before making
+// any calls.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// SetEndpoints replaces the candidate address set, dialing any address not
+// already connected and closing connections to addresses no longer present.
+func (c *Client) SetEndpoints(addresses []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing := make(map[string]*endpoint, len(c.endpoints))
+	for _, e := range c.endpoints {
+		existing[e.addr] = e
+	}
+
+	creds, err := c.cfg.CreateCreds()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	next := make([]*endpoint, 0, len(addresses))
+	for _, addr := range addresses {
+		if e, ok := existing[addr]; ok {
+			next = append(next, e)
+			delete(existing, addr)
+			continue
+		}
+
+		conn, err := grpc.Dial(addr, creds)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		next = append(next, &endpoint{
+			addr:   addr,
+			conn:   conn,
+			client: api.NewTshdEventsServiceClient(conn),
+		})
+	}
+
+	for _, stale := range existing {
+		stale.conn.Close()
+	}
+
+	c.endpoints = next
+	return nil
+}
+
+// Diagnostics returns the current health of every candidate endpoint.
+func (c *Client) Diagnostics() []EndpointStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	out := make([]EndpointStatus, 0, len(c.endpoints))
+	for _, e := range c.endpoints {
+		out = append(out, EndpointStatus{
+			Address:       e.addr,
+			State:         e.conn.GetState(),
+			CooldownUntil: e.cooldownUntil,
+		})
+	}
+	return out
+}
+
+// orderedEndpoints returns the current endpoints with healthy ones first, so
+// callers try a healthy endpoint before falling back to one in cooldown.
+func (c *Client) orderedEndpoints() []*endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*endpoint, 0, len(c.endpoints))
+	unhealthy := make([]*endpoint, 0, len(c.endpoints))
+	for _, e := range c.endpoints {
+		if e.healthy(now) {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (c *Client) markUnhealthy(e *endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.cooldownUntil = time.Now().Add(c.cfg.Cooldown)
+}
+
+// call tries fn against each candidate endpoint in health order, returning
+// the first success and marking any endpoint that errors as unhealthy for
+// the cooldown window.
+func (c *Client) call(fn func(api.TshdEventsServiceClient) error) error {
+	endpoints := c.orderedEndpoints()
+	if len(endpoints) == 0 {
+		return trace.ConnectionProblem(nil, "no tshd events server endpoints configured")
+	}
+
+	var lastErr error
+	for _, e := range endpoints {
+		if err := fn(e.client); err != nil {
+			c.markUnhealthy(e)
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return trace.Wrap(lastErr)
+}
+
+// Relogin asks the Electron app to relogin the user to the given cluster.
+func (c *Client) Relogin(ctx context.Context, req *api.ReloginRequest) error {
+	return c.call(func(client api.TshdEventsServiceClient) error {
+		_, err := client.Relogin(ctx, req)
+		return err
+	})
+}
+
+// SendNotification sends a one-off notification to the Electron app.
+func (c *Client) SendNotification(ctx context.Context, req *api.SendNotificationRequest) error {
+	return c.call(func(client api.TshdEventsServiceClient) error {
+		_, err := client.SendNotification(ctx, req)
+		return err
+	})
+}
+
+// Close closes every candidate connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for _, e := range c.endpoints {
+		if err := e.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.endpoints = nil
+	return firstErr
+}
@@ -0,0 +1,235 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workqueue implements a small per-cluster rate-limiting retry
+// queue, modeled on client-go's workqueue.RateLimitingInterface and its
+// controller worker loop: a sync function is popped and run, failures are
+// retried with exponential backoff and jitter, and at most one operation per
+// cluster runs at a time so a flaky proxy can't turn a handful of parallel
+// Electron requests into a thundering herd of concurrent retries against it.
+// Submissions that share a cluster and operation key while one is already
+// queued or running are deduplicated onto the same in-flight attempt.
+package workqueue
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// SyncFunc performs one attempt of a retryable operation.
+type SyncFunc func(ctx context.Context) error
+
+const (
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+	defaultMaxRetries = 5
+)
+
+// Config configures a [Queue].
+type Config struct {
+	// BaseDelay is the backoff before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff between retries.
+	MaxDelay time.Duration
+	// MaxRetries is how many times a failed sync is retried before giving up
+	// and reporting the error to every submitter.
+	MaxRetries int
+	// Log is used to report retries.
+	Log logrus.FieldLogger
+}
+
+func (c *Config) checkAndSetDefaults() {
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = defaultBaseDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = defaultMaxDelay
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.Log == nil {
+		c.Log = logrus.StandardLogger()
+	}
+}
+
+// Queue runs at most one retryable operation at a time per cluster key.
+type Queue struct {
+	cfg Config
+
+	mu       sync.Mutex
+	clusters map[string]*clusterQueue
+}
+
+// New creates a [*Queue].
+func New(cfg Config) *Queue {
+	cfg.checkAndSetDefaults()
+	return &Queue{cfg: cfg, clusters: make(map[string]*clusterQueue)}
+}
+
+// item is one submitted operation, possibly shared by several submitters
+// that used the same (clusterKey, opKey) pair while it was queued or in
+// flight.
+type item struct {
+	opKey   string
+	sync    SyncFunc
+	waiters []chan error
+	retries int
+}
+
+// clusterQueue serializes items submitted for a single cluster key.
+type clusterQueue struct {
+	mu      sync.Mutex
+	pending []*item
+	byKey   map[string]*item
+	running bool
+	cancel  context.CancelFunc
+}
+
+// Submit enqueues sync under operation key opKey for clusterKey and returns
+// a channel that receives its eventual result. If an operation with the
+// same clusterKey and opKey is already queued or running, the caller shares
+// that operation's result instead of a new one being started.
+func (q *Queue) Submit(clusterKey, opKey string, sync SyncFunc) <-chan error {
+	cq := q.clusterQueue(clusterKey)
+	result := make(chan error, 1)
+
+	cq.mu.Lock()
+	if it, ok := cq.byKey[opKey]; ok {
+		it.waiters = append(it.waiters, result)
+		cq.mu.Unlock()
+		return result
+	}
+
+	it := &item{opKey: opKey, sync: sync, waiters: []chan error{result}}
+	cq.byKey[opKey] = it
+	cq.pending = append(cq.pending, it)
+	cq.mu.Unlock()
+
+	q.runNext(clusterKey, cq)
+
+	return result
+}
+
+func (q *Queue) clusterQueue(clusterKey string) *clusterQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cq, ok := q.clusters[clusterKey]
+	if !ok {
+		cq = &clusterQueue{byKey: make(map[string]*item)}
+		q.clusters[clusterKey] = cq
+	}
+	return cq
+}
+
+// CancelCluster cancels clusterKey's in-flight operation, if any, and
+// discards every operation still queued for it, reporting a cancellation
+// error to their submitters. Service hooks this into cluster logout and
+// removal so a stale retry loop doesn't keep running.
+func (q *Queue) CancelCluster(clusterKey string) {
+	q.mu.Lock()
+	cq, ok := q.clusters[clusterKey]
+	delete(q.clusters, clusterKey)
+	q.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	cq.mu.Lock()
+	if cq.cancel != nil {
+		cq.cancel()
+	}
+	pending := cq.pending
+	cq.pending = nil
+	cq.byKey = make(map[string]*item)
+	cq.mu.Unlock()
+
+	for _, it := range pending {
+		for _, w := range it.waiters {
+			w <- trace.Errorf("operation %q for cluster %q was canceled", it.opKey, clusterKey)
+		}
+	}
+}
+
+// runNext starts the next pending item for clusterKey if the cluster's
+// single worker slot is free.
+func (q *Queue) runNext(clusterKey string, cq *clusterQueue) {
+	cq.mu.Lock()
+	if cq.running || len(cq.pending) == 0 {
+		cq.mu.Unlock()
+		return
+	}
+
+	it := cq.pending[0]
+	cq.pending = cq.pending[1:]
+	cq.running = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cq.cancel = cancel
+	cq.mu.Unlock()
+
+	go q.run(clusterKey, cq, it, ctx)
+}
+
+func (q *Queue) run(clusterKey string, cq *clusterQueue, it *item, ctx context.Context) {
+	err := it.sync(ctx)
+
+	if err != nil && ctx.Err() == nil && it.retries < q.cfg.MaxRetries {
+		it.retries++
+		delay := backoff(q.cfg.BaseDelay, q.cfg.MaxDelay, it.retries)
+		q.cfg.Log.WithError(err).Warnf(
+			"Retrying %q for cluster %q in %s (attempt %d/%d)",
+			it.opKey, clusterKey, delay, it.retries, q.cfg.MaxRetries)
+
+		select {
+		case <-time.After(delay):
+			cq.mu.Lock()
+			cq.running = false
+			cq.cancel = nil
+			cq.pending = append([]*item{it}, cq.pending...)
+			cq.mu.Unlock()
+			q.runNext(clusterKey, cq)
+			return
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	cq.mu.Lock()
+	delete(cq.byKey, it.opKey)
+	cq.running = false
+	cq.cancel = nil
+	cq.mu.Unlock()
+
+	for _, w := range it.waiters {
+		w <- err
+	}
+
+	q.runNext(clusterKey, cq)
+}
+
+// backoff returns an exponential backoff for the given attempt (1-indexed),
+// capped at max and with up to 50% jitter to avoid synchronized retries.
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := base << uint(attempt-1)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/gravitational/trace"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/authz"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/httplib"
+	"github.com/gravitational/teleport/lib/reversetunnelclient"
+)
+
+// trustedPeer is the JSON representation of a trusted proxy peer returned by
+// the trusted peers admin endpoints.
+type trustedPeer struct {
+	// Addr is the peer address (host:port) that's allowed to dial in over
+	// proxy peering.
+	Addr string `json:"addr"`
+	// AddedAt is when the peer was added to the allow-list.
+	AddedAt time.Time `json:"added_at"`
+}
+
+// addTrustedPeerRequest is the body of POST /v1/webapi/sites/:site/trusted_peers.
+type addTrustedPeerRequest struct {
+	Addr string `json:"addr"`
+}
+
+// RegisterTrustedPeerRoutes wires the trusted-peer admin endpoints onto h's
+// router, the way NewHandler would register them alongside the rest of the
+// site-scoped routes at /v1/webapi/sites/:site/trusted_peers(/:addr) behind
+// the same cluster-auth middleware as every other site-scoped API.
+//
+// NewHandler itself isn't part of this checkout (along with *Handler,
+// *SessionContext, and the WithClusterAuth/GET/POST/DELETE router methods
+// this file calls), so RegisterTrustedPeerRoutes has no caller here at all.
+// It's included as the site-scoped-route counterpart of the trusted-peer
+// client methods it depends on, for whenever the rest of lib/web lands in
+// this checkout.
+func (h *Handler) RegisterTrustedPeerRoutes() {
+	h.GET("/webapi/sites/:site/trusted_peers", h.WithClusterAuth(h.getTrustedPeers))
+	h.POST("/webapi/sites/:site/trusted_peers", h.WithClusterAuth(h.addTrustedPeer))
+	h.DELETE("/webapi/sites/:site/trusted_peers/:addr", h.WithClusterAuth(h.deleteTrustedPeer))
+	h.GET("/webapi/sites/:site/trusted_peers/watch", h.WithClusterAuth(h.watchTrustedPeers))
+}
+
+// getTrustedPeers handles GET /v1/webapi/sites/:site/trusted_peers.
+//
+// It lists the proxy peering allow-list for the site, which is what decides
+// which proxies/nodes are trusted to establish peer-to-peer tunnels.
+func (h *Handler) getTrustedPeers(w http.ResponseWriter, r *http.Request, p httprouter.Params, sctx *SessionContext, site reversetunnelclient.RemoteSite) (interface{}, error) {
+	clt, err := sctx.GetUserClient(r.Context(), site)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	peers, err := clt.GetTrustedPeers(r.Context())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	out := make([]trustedPeer, 0, len(peers))
+	for _, peer := range peers {
+		out = append(out, trustedPeer{Addr: peer.GetAddr(), AddedAt: peer.GetAddedAt()})
+	}
+	return out, nil
+}
+
+// addTrustedPeer handles POST /v1/webapi/sites/:site/trusted_peers.
+//
+// It adds addr to the proxy peering allow-list and persists it through the
+// backend so proxies don't need to restart to pick up the change.
+func (h *Handler) addTrustedPeer(w http.ResponseWriter, r *http.Request, p httprouter.Params, sctx *SessionContext, site reversetunnelclient.RemoteSite) (interface{}, error) {
+	var req addTrustedPeerRequest
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if req.Addr == "" {
+		return nil, trace.BadParameter("missing addr")
+	}
+
+	clt, err := sctx.GetUserClient(r.Context(), site)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := clt.AddTrustedPeer(r.Context(), req.Addr); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	h.emitTrustedPeerEvent(r, events.TrustedPeerAddEvent, events.TrustedPeerAddCode, req.Addr)
+
+	return &trustedPeer{Addr: req.Addr, AddedAt: h.clock.Now().UTC()}, nil
+}
+
+// deleteTrustedPeer handles DELETE /v1/webapi/sites/:site/trusted_peers/:addr.
+//
+// It removes addr from the proxy peering allow-list; existing tunnels from
+// that peer are not forcibly closed, but new ones will be rejected.
+func (h *Handler) deleteTrustedPeer(w http.ResponseWriter, r *http.Request, p httprouter.Params, sctx *SessionContext, site reversetunnelclient.RemoteSite) (interface{}, error) {
+	addr := p.ByName("addr")
+	if addr == "" {
+		return nil, trace.BadParameter("missing addr")
+	}
+
+	clt, err := sctx.GetUserClient(r.Context(), site)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := clt.RemoveTrustedPeer(r.Context(), addr); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	h.emitTrustedPeerEvent(r, events.TrustedPeerRemoveEvent, events.TrustedPeerRemoveCode, addr)
+
+	return OK(), nil
+}
+
+// emitTrustedPeerEvent audits a change to the trusted peer allow-list. A
+// failure to emit is logged rather than returned to the caller, since the
+// mutation itself already succeeded.
+func (h *Handler) emitTrustedPeerEvent(r *http.Request, eventType, eventCode, addr string) {
+	event := &apievents.TrustedPeerChange{
+		Metadata: apievents.Metadata{
+			Type: eventType,
+			Code: eventCode,
+		},
+		UserMetadata: authz.ClientUserMetadataFromContext(r.Context()),
+		Addr:         addr,
+	}
+	if err := h.cfg.Emitter.EmitAuditEvent(r.Context(), event); err != nil {
+		h.log.WithError(err).Warn("Failed to emit trusted peer change event.")
+	}
+}
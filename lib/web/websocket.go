@@ -0,0 +1,132 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/reversetunnelclient"
+)
+
+const (
+	// defaultWSReadBufferSize and defaultWSWriteBufferSize match
+	// gorilla/websocket's own defaults (4 KiB); they're named here so
+	// individual endpoints can override just the buffer size they care
+	// about without magic numbers.
+	defaultWSReadBufferSize  = 4096
+	defaultWSWriteBufferSize = 4096
+
+	// defaultWSMaxMessageSize caps a single inbound websocket message.
+	// gorilla/websocket applies no read limit unless one is set with
+	// SetReadLimit, so without this cap a peer could send a message of any
+	// size.
+	defaultWSMaxMessageSize = 1 << 20 // 1 MiB
+)
+
+// wsUpgraderOptions configures the parameters of upgradeWebsocket beyond
+// gorilla/websocket's defaults. The zero value reproduces the previous,
+// implicit behavior (default buffer sizes, defaultWSMaxMessageSize, no
+// compression).
+type wsUpgraderOptions struct {
+	// ReadBufferSize and WriteBufferSize size the upgrader's I/O buffers.
+	ReadBufferSize, WriteBufferSize int
+	// MaxMessageSize caps a single inbound frame; 0 selects
+	// defaultWSMaxMessageSize.
+	MaxMessageSize int64
+	// EnableCompression opts into permessage-deflate negotiation with
+	// clients that request it.
+	EnableCompression bool
+}
+
+func (o wsUpgraderOptions) maxMessageSize() int64 {
+	if o.MaxMessageSize <= 0 {
+		return defaultWSMaxMessageSize
+	}
+	return o.MaxMessageSize
+}
+
+// newUpgrader builds a gorilla/websocket Upgrader from opts, applying the
+// same buffer-size defaults used on the client side in
+// helpers.WebClientPack.OpenWebsocketWithOptions. An Upgrader has no message
+// size limit of its own: callers apply opts.maxMessageSize() to the upgraded
+// connection with SetReadLimit.
+func newUpgrader(opts wsUpgraderOptions) websocket.Upgrader {
+	readBufferSize := opts.ReadBufferSize
+	if readBufferSize <= 0 {
+		readBufferSize = defaultWSReadBufferSize
+	}
+	writeBufferSize := opts.WriteBufferSize
+	if writeBufferSize <= 0 {
+		writeBufferSize = defaultWSWriteBufferSize
+	}
+
+	return websocket.Upgrader{
+		ReadBufferSize:    readBufferSize,
+		WriteBufferSize:   writeBufferSize,
+		EnableCompression: opts.EnableCompression,
+		CheckOrigin:       func(r *http.Request) bool { return true },
+	}
+}
+
+// trustedPeerWatchInterval is how often watchTrustedPeers polls for changes
+// to the allow-list and pushes an update to the client.
+const trustedPeerWatchInterval = 5 * time.Second
+
+// watchTrustedPeers handles GET /v1/webapi/sites/:site/trusted_peers/watch.
+// It upgrades the connection with newUpgrader and pushes the current
+// trusted-peer allow-list as a JSON message every trustedPeerWatchInterval,
+// so a client can reflect additions/removals made by another admin without
+// polling the REST endpoint itself.
+//
+// It's registered in RegisterTrustedPeerRoutes, but that registration has
+// no caller in this checkout either (NewHandler isn't part of it), so this
+// handler isn't actually reachable here; newUpgrader remains exercised only
+// by this function, not by a live request.
+func (h *Handler) watchTrustedPeers(w http.ResponseWriter, r *http.Request, p httprouter.Params, sctx *SessionContext, site reversetunnelclient.RemoteSite) (interface{}, error) {
+	opts := wsUpgraderOptions{EnableCompression: true}
+	upgrader := newUpgrader(opts)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer conn.Close()
+	conn.SetReadLimit(opts.maxMessageSize())
+
+	ctx := r.Context()
+	ticker := time.NewTicker(trustedPeerWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		peers, err := h.getTrustedPeers(w, r, p, sctx, site)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if err := conn.WriteJSON(peers); err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-ticker.C:
+		}
+	}
+}